@@ -0,0 +1,243 @@
+package config
+
+import (
+	"time"
+)
+
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+const (
+	ErrorFormatEnvelope = "envelope"
+	ErrorFormatProblem  = "problem"
+)
+
+// Config is the typed application configuration. Every field is read from
+// the key in its env tag; see Load for where keys are looked up. Durations
+// accept Go duration strings ("15m", "72h"), and plain integers are read in
+// the unit of the unit tag, minutes when it is missing.
+type Config struct {
+	Env         string `env:"ENV" default:"development"`
+	AutoMigrate bool   `env:"AUTO_MIGRATE"`
+
+	Log           Log
+	Server        Server
+	API           API
+	I18n          I18n
+	Database      Database
+	JWT           JWT
+	Cookie        Cookie
+	Mailer        Mailer
+	MagicLink     MagicLink
+	SMS           SMS
+	OTP           OTP
+	WebAuthn      WebAuthn
+	OAuth         OAuth
+	LDAP          LDAP
+	SCIM          SCIM
+	Impersonation Impersonation
+	Organization  Organization
+	Tracing       Tracing
+}
+
+type Log struct {
+	Level   string   `env:"LOG_LEVEL" default:"info"`
+	Format  string   `env:"LOG_FORMAT" default:"text"`
+	Outputs []string `env:"LOG_OUTPUTS" default:"file"`
+	File    string   `env:"LOG_FILE" default:"app.log"`
+	// The file is rotated when it reaches MaxSizeMB and every RotateInterval
+	// (0 disables time-based rotation). MaxBackups rotated files are kept,
+	// none older than MaxAge.
+	MaxSizeMB      int           `env:"LOG_MAX_SIZE_MB" default:"100"`
+	RotateInterval time.Duration `env:"LOG_ROTATE_INTERVAL" default:"24h" unit:"h"`
+	MaxBackups     int           `env:"LOG_MAX_BACKUPS" default:"7"`
+	MaxAge         time.Duration `env:"LOG_MAX_AGE" default:"720h" unit:"h"`
+	Compress       bool          `env:"LOG_COMPRESS" default:"true"`
+	// RedactKeys are masked in addition to password, token, dsn, secret and
+	// authorization. With HashPII usernames, emails and phone numbers are
+	// replaced by an HMAC keyed with PIIHashKey.
+	RedactKeys []string `env:"LOG_REDACT_KEYS"`
+	HashPII    bool     `env:"LOG_HASH_PII"`
+	PIIHashKey string   `env:"LOG_PII_HASH_KEY"`
+	// DeliveryContent makes the log mailer and SMS drivers write the magic
+	// links and OTP codes they send instead of masking them.
+	DeliveryContent bool `env:"LOG_DELIVERY_CONTENT"`
+}
+
+type Server struct {
+	Port         string        `env:"PORT" default:"8080"`
+	ReadTimeout  time.Duration `env:"SERVER_READ_TIMEOUT" default:"15s" unit:"s"`
+	WriteTimeout time.Duration `env:"SERVER_WRITE_TIMEOUT" default:"15s" unit:"s"`
+	IdleTimeout  time.Duration `env:"SERVER_IDLE_TIMEOUT" default:"60s" unit:"s"`
+	// MetricsPort serves /metrics on a listener of its own, so it is not
+	// reachable through the public port.
+	MetricsPort string `env:"METRICS_PORT" default:"9090"`
+	// DrainDelay is how long the server keeps serving after reporting
+	// not-ready, so load balancers stop routing to it before it drains.
+	DrainDelay time.Duration `env:"SERVER_DRAIN_DELAY" default:"5s" unit:"s"`
+	// ShutdownTimeout bounds draining requests, stopping workers and closing
+	// the database.
+	ShutdownTimeout time.Duration `env:"SERVER_SHUTDOWN_TIMEOUT" default:"30s" unit:"s"`
+	// ErrorFormat is "envelope" or "problem" (RFC 7807). Clients sending
+	// Accept: application/problem+json get problem details either way.
+	ErrorFormat string `env:"ERROR_FORMAT" default:"envelope"`
+}
+
+// API configures the unversioned paths, kept as aliases of v1 while clients
+// migrate. They answer with a Deprecation header dated UnversionedDeprecation
+// and, once it is set, a Sunset header dated UnversionedSunset. Dates are
+// YYYY-MM-DD.
+type API struct {
+	ServeUnversioned       bool   `env:"API_SERVE_UNVERSIONED" default:"true"`
+	UnversionedDeprecation string `env:"API_UNVERSIONED_DEPRECATION" default:"2026-10-19"`
+	UnversionedSunset      string `env:"API_UNVERSIONED_SUNSET"`
+}
+
+// UnversionedDeprecationDate returns UnversionedDeprecation, zero when unset.
+// Validate has checked it parses.
+func (a API) UnversionedDeprecationDate() time.Time {
+	return parseDate(a.UnversionedDeprecation)
+}
+
+// UnversionedSunsetDate returns UnversionedSunset, zero when unset.
+func (a API) UnversionedSunsetDate() time.Time {
+	return parseDate(a.UnversionedSunset)
+}
+
+func parseDate(value string) time.Time {
+	date, _ := time.Parse(time.DateOnly, value)
+
+	return date
+}
+
+type I18n struct {
+	// DefaultLanguage is served when Accept-Language matches no supported
+	// language.
+	DefaultLanguage string `env:"DEFAULT_LANGUAGE" default:"en"`
+}
+
+type Database struct {
+	Host     string `env:"DB_HOST"`
+	Port     string `env:"DB_PORT" default:"5432"`
+	User     string `env:"DB_USER"`
+	Password string `env:"DB_PASS"`
+	Name     string `env:"DB_NAME"`
+	TimeZone string `env:"TIMEZONE"`
+}
+
+type JWT struct {
+	Issuer               string        `env:"APP_NAME"`
+	Secret               string        `env:"JWT_SECRET"`
+	AccessTokenDuration  time.Duration `env:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration time.Duration `env:"REFRESH_TOKEN_DURATION"`
+}
+
+type Cookie struct {
+	Enabled  bool   `env:"AUTH_COOKIE_ENABLED"`
+	Domain   string `env:"COOKIE_DOMAIN"`
+	Path     string `env:"COOKIE_PATH" default:"/"`
+	SameSite string `env:"COOKIE_SAME_SITE" default:"lax"`
+	Secure   bool   `env:"COOKIE_SECURE" default:"true"`
+}
+
+type Mailer struct {
+	Driver       string `env:"MAILER_DRIVER" default:"log"`
+	SMTPHost     string `env:"SMTP_HOST"`
+	SMTPPort     string `env:"SMTP_PORT" default:"587"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD"`
+	SMTPFrom     string `env:"SMTP_FROM"`
+}
+
+type MagicLink struct {
+	URL      string        `env:"MAGIC_LINK_URL"`
+	Duration time.Duration `env:"MAGIC_LINK_DURATION" default:"15m"`
+}
+
+type SMS struct {
+	Driver        string `env:"SMS_DRIVER" default:"log"`
+	GatewayURL    string `env:"SMS_GATEWAY_URL"`
+	GatewayAPIKey string `env:"SMS_GATEWAY_API_KEY"`
+}
+
+type OTP struct {
+	Duration    time.Duration `env:"OTP_DURATION" default:"5m"`
+	MaxAttempts int           `env:"OTP_MAX_ATTEMPTS" default:"5"`
+	// RequestLimit codes can be sent to a phone number per RequestWindow.
+	RequestLimit  int           `env:"OTP_REQUEST_LIMIT" default:"5"`
+	RequestWindow time.Duration `env:"OTP_REQUEST_WINDOW" default:"1h"`
+}
+
+// WebAuthn enables passkeys when WEBAUTHN_RP_ID is set.
+type WebAuthn struct {
+	RPID              string        `env:"WEBAUTHN_RP_ID"`
+	RPDisplayName     string        `env:"WEBAUTHN_RP_DISPLAY_NAME"`
+	RPOrigins         []string      `env:"WEBAUTHN_RP_ORIGINS"`
+	ChallengeDuration time.Duration `env:"WEBAUTHN_CHALLENGE_DURATION" default:"5m"`
+}
+
+type OAuth struct {
+	StateDuration time.Duration `env:"OAUTH_STATE_DURATION" default:"10m"`
+	// Providers holds one entry per name listed in OAUTH_PROVIDERS, read from
+	// the OAUTH_<NAME>_* keys.
+	Providers []OAuthProvider
+}
+
+type OAuthProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type LDAP struct {
+	// DefaultAuthenticator is "database" or "ldap"; Domains lists username
+	// domains always routed to LDAP. GroupRoles has the form
+	// "groupDN:role;groupDN:role".
+	DefaultAuthenticator string            `env:"AUTH_DEFAULT_AUTHENTICATOR" default:"database"`
+	URL                  string            `env:"LDAP_URL"`
+	StartTLS             bool              `env:"LDAP_START_TLS"`
+	BindDN               string            `env:"LDAP_BIND_DN"`
+	BindPassword         string            `env:"LDAP_BIND_PASSWORD"`
+	BaseDN               string            `env:"LDAP_BASE_DN"`
+	UserFilter           string            `env:"LDAP_USER_FILTER"`
+	EmailAttribute       string            `env:"LDAP_EMAIL_ATTRIBUTE"`
+	GroupAttribute       string            `env:"LDAP_GROUP_ATTRIBUTE"`
+	GroupRoles           map[string]string `env:"LDAP_GROUP_ROLES"`
+	Domains              []string          `env:"LDAP_DOMAINS"`
+}
+
+type SCIM struct {
+	BearerToken string `env:"SCIM_BEARER_TOKEN"`
+}
+
+type Impersonation struct {
+	TokenDuration time.Duration `env:"IMPERSONATION_TOKEN_DURATION" default:"15m"`
+}
+
+type Organization struct {
+	InvitationURL      string        `env:"ORG_INVITATION_URL"`
+	InvitationDuration time.Duration `env:"ORG_INVITATION_DURATION" default:"72h" unit:"h"`
+}
+
+type Tracing struct {
+	Enabled     bool   `env:"TRACING_ENABLED"`
+	ServiceName string `env:"OTEL_SERVICE_NAME" default:"kfc_be"`
+	// OTLPEndpoint is the base URL of an OTLP/HTTP collector. Spans are
+	// printed to stdout when it is empty.
+	OTLPEndpoint string  `env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	SampleRatio  float64 `env:"TRACING_SAMPLE_RATIO" default:"1"`
+}
+
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+func (w WebAuthn) Enabled() bool {
+	return w.RPID != ""
+}
@@ -0,0 +1,319 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/config"
+)
+
+// requiredEnv holds the keys without a default that Validate requires.
+var requiredEnv = map[string]string{
+	"DB_HOST":                "localhost",
+	"DB_USER":                "kfc",
+	"DB_NAME":                "kfc",
+	"JWT_SECRET":             "0123456789abcdef0123456789abcdef",
+	"ACCESS_TOKEN_DURATION":  "15",
+	"REFRESH_TOKEN_DURATION": "1440",
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	secretFile := writeFile(t, dir, "jwt_secret", "from-file\n")
+	yamlFile := writeFile(t, dir, "config.yaml", "db:\n  host: yaml-host\n  name: yaml-name\nlog:\n  redact-keys: [pin, cvv]\n")
+	tomlFile := writeFile(t, dir, "config.toml", "[db]\nhost = \"toml-host\"\n")
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		check func(t *testing.T, c *config.Config)
+		// wantProblems are substrings of the problems Load reports
+		wantProblems []string
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "Env", c.Env, config.EnvDevelopment)
+				equal(t, "Server.Port", c.Server.Port, "8080")
+				equal(t, "Server.MetricsPort", c.Server.MetricsPort, "9090")
+				equal(t, "Server.ReadTimeout", c.Server.ReadTimeout, 15*time.Second)
+				equal(t, "Cookie.Secure", c.Cookie.Secure, true)
+				equal(t, "OTP.MaxAttempts", c.OTP.MaxAttempts, 5)
+				equal(t, "Log.DeliveryContent", c.Log.DeliveryContent, false)
+			},
+		},
+		{
+			name: "plain integers use the unit of the field",
+			env:  map[string]string{"OTP_DURATION": "10", "SERVER_READ_TIMEOUT": "30", "LOG_MAX_AGE": "48"},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "OTP.Duration", c.OTP.Duration, 10*time.Minute)
+				equal(t, "Server.ReadTimeout", c.Server.ReadTimeout, 30*time.Second)
+				equal(t, "Log.MaxAge", c.Log.MaxAge, 48*time.Hour)
+			},
+		},
+		{
+			name: "duration strings",
+			env:  map[string]string{"OTP_DURATION": "90s", "LOG_ROTATE_INTERVAL": "1h30m"},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "OTP.Duration", c.OTP.Duration, 90*time.Second)
+				equal(t, "Log.RotateInterval", c.Log.RotateInterval, 90*time.Minute)
+			},
+		},
+		{
+			name: "lists drop blank items",
+			env:  map[string]string{"LOG_REDACT_KEYS": " pin, ,cvv "},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "Log.RedactKeys", c.Log.RedactKeys, []string{"pin", "cvv"})
+			},
+		},
+		{
+			name: "secret read from a file",
+			env:  map[string]string{"JWT_SECRET_FILE": secretFile},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "JWT.Secret", c.JWT.Secret, "from-file")
+			},
+		},
+		{
+			name: "environment wins over the secret file",
+			env:  map[string]string{"JWT_SECRET": "from-env", "JWT_SECRET_FILE": secretFile},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "JWT.Secret", c.JWT.Secret, "from-env")
+			},
+		},
+		{
+			name: "yaml config file",
+			env:  map[string]string{"CONFIG_FILE": yamlFile, "DB_NAME": "env-name"},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "Database.Host", c.Database.Host, "yaml-host")
+				equal(t, "Database.Name", c.Database.Name, "env-name")
+				equal(t, "Log.RedactKeys", c.Log.RedactKeys, []string{"pin", "cvv"})
+			},
+		},
+		{
+			name: "toml config file",
+			env:  map[string]string{"CONFIG_FILE": tomlFile},
+			check: func(t *testing.T, c *config.Config) {
+				equal(t, "Database.Host", c.Database.Host, "toml-host")
+			},
+		},
+		{
+			name: "oauth providers",
+			env: map[string]string{
+				"OAUTH_PROVIDERS":         "mock, google",
+				"OAUTH_MOCK_CLIENT_ID":    "kfc_be",
+				"OAUTH_MOCK_SCOPES":       "openid,email",
+				"OAUTH_GOOGLE_ISSUER_URL": "https://accounts.google.com",
+			},
+			check: func(t *testing.T, c *config.Config) {
+				if len(c.OAuth.Providers) != 2 {
+					t.Fatalf("OAuth.Providers = %+v, want 2 providers", c.OAuth.Providers)
+				}
+				equal(t, "mock ClientID", c.OAuth.Providers[0].ClientID, "kfc_be")
+				equal(t, "mock Scopes", c.OAuth.Providers[0].Scopes, []string{"openid", "email"})
+				equal(t, "google IssuerURL", c.OAuth.Providers[1].IssuerURL, "https://accounts.google.com")
+			},
+		},
+		{
+			name:         "every unparsable value is reported",
+			env:          map[string]string{"OTP_MAX_ATTEMPTS": "five", "AUTH_COOKIE_ENABLED": "maybe", "OTP_DURATION": "soon"},
+			wantProblems: []string{"OTP_MAX_ATTEMPTS", "AUTH_COOKIE_ENABLED", "OTP_DURATION"},
+		},
+		{
+			name:         "missing secret file",
+			env:          map[string]string{"JWT_SECRET_FILE": filepath.Join(dir, "missing")},
+			wantProblems: []string{"JWT_SECRET_FILE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+
+			c, err := config.Load()
+			if len(tt.wantProblems) > 0 {
+				assertProblems(t, err, tt.wantProblems)
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			tt.check(t, c)
+		})
+	}
+}
+
+func TestLoadRejectsUnknownConfigFileFormat(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeFile(t, t.TempDir(), "config.json", "{}"))
+
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("Load() error = %v, want unsupported format", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(c *config.Config)
+		// wantProblems are substrings of the problems Validate reports, none
+		// when empty
+		wantProblems []string
+	}{
+		{
+			name:   "valid",
+			mutate: func(c *config.Config) {},
+		},
+		{
+			name: "required database keys",
+			mutate: func(c *config.Config) {
+				c.Database.Host = ""
+				c.Database.Port = "pg"
+			},
+			wantProblems: []string{"DB_HOST is required", "DB_PORT must be a number"},
+		},
+		{
+			name:         "unknown environment",
+			mutate:       func(c *config.Config) { c.Env = "prod" },
+			wantProblems: []string{"ENV must be one of"},
+		},
+		{
+			name: "short secret in production",
+			mutate: func(c *config.Config) {
+				c.Env = config.EnvProduction
+				c.JWT.Secret = "short"
+			},
+			wantProblems: []string{"JWT_SECRET must be at least 32 characters"},
+		},
+		{
+			name: "short secret outside production",
+			mutate: func(c *config.Config) {
+				c.JWT.Secret = "short"
+			},
+		},
+		{
+			name: "refresh shorter than access",
+			mutate: func(c *config.Config) {
+				c.JWT.RefreshTokenDuration = time.Minute
+			},
+			wantProblems: []string{"REFRESH_TOKEN_DURATION must not be shorter"},
+		},
+		{
+			name: "insecure cookies in production",
+			mutate: func(c *config.Config) {
+				c.Env = config.EnvProduction
+				c.Cookie.Enabled = true
+				c.Cookie.Secure = false
+			},
+			wantProblems: []string{"COOKIE_SECURE must be true in production"},
+		},
+		{
+			name: "same site none needs secure cookies",
+			mutate: func(c *config.Config) {
+				c.Cookie.SameSite = "None"
+				c.Cookie.Secure = false
+			},
+			wantProblems: []string{"COOKIE_SECURE must be true when COOKIE_SAME_SITE is none"},
+		},
+		{
+			name:         "metrics on the api port",
+			mutate:       func(c *config.Config) { c.Server.MetricsPort = c.Server.Port },
+			wantProblems: []string{"METRICS_PORT must differ from PORT"},
+		},
+		{
+			name: "delivery content in production",
+			mutate: func(c *config.Config) {
+				c.Env = config.EnvProduction
+				c.Log.DeliveryContent = true
+			},
+			wantProblems: []string{"LOG_DELIVERY_CONTENT must be false in production"},
+		},
+		{
+			name:         "smtp without host",
+			mutate:       func(c *config.Config) { c.Mailer.Driver = "smtp" },
+			wantProblems: []string{"SMTP_HOST is required", "SMTP_FROM is required"},
+		},
+		{
+			name: "oauth provider without client",
+			mutate: func(c *config.Config) {
+				c.OAuth.Providers = []config.OAuthProvider{{Name: "mock", IssuerURL: "http://localhost:8090"}}
+			},
+			wantProblems: []string{"OAUTH_MOCK_CLIENT_ID is required", "OAUTH_MOCK_REDIRECT_URL is required"},
+		},
+		{
+			name:         "sunset before deprecation",
+			mutate:       func(c *config.Config) { c.API.UnversionedSunset = "2000-01-01" },
+			wantProblems: []string{"API_UNVERSIONED_SUNSET must not be before"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range requiredEnv {
+				t.Setenv(key, value)
+			}
+
+			c, err := config.Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.mutate(c)
+
+			err = c.Validate()
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			assertProblems(t, err, tt.wantProblems)
+		})
+	}
+}
+
+func assertProblems(t *testing.T, err error, want []string) {
+	t.Helper()
+
+	var validationErr *config.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want a *config.ValidationError", err)
+	}
+
+	for _, problem := range want {
+		found := false
+		for _, got := range validationErr.Problems {
+			if strings.Contains(got, problem) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("problems %q do not mention %q", validationErr.Problems, problem)
+		}
+	}
+}
+
+func equal(t *testing.T, field string, got any, want any) {
+	t.Helper()
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("%s = %v, want %v", field, got, want)
+	}
+}
+
+func writeFile(t *testing.T, dir string, name string, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
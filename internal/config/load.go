@@ -0,0 +1,300 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lpernett/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// KeyConfigFile names an optional YAML or TOML file holding the same keys as
+// the environment.
+const KeyConfigFile = "CONFIG_FILE"
+
+// fileSuffix marks a key whose value is read from the file at the given
+// path, e.g. JWT_SECRET_FILE=/run/secrets/jwt_secret.
+const fileSuffix = "_FILE"
+
+const (
+	keyOAuthProviders          = "OAUTH_PROVIDERS"
+	keyOAuthIssuerURLFormat    = "OAUTH_%s_ISSUER_URL"
+	keyOAuthClientIDFormat     = "OAUTH_%s_CLIENT_ID"
+	keyOAuthClientSecretFormat = "OAUTH_%s_CLIENT_SECRET"
+	keyOAuthRedirectURLFormat  = "OAUTH_%s_REDIRECT_URL"
+	keyOAuthScopesFormat       = "OAUTH_%s_SCOPES"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Load reads the configuration. A key is taken from, in order: the process
+// environment (including .env, which never overrides it), the file named by
+// <KEY>_FILE, the config file named by CONFIG_FILE, and the default tag. Values
+// that cannot be parsed are all reported in a single *ValidationError; call
+// Validate to check the loaded values.
+func Load() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("loading .env: %w", err)
+	}
+
+	fileValues, err := readConfigFile(os.Getenv(KeyConfigFile))
+	if err != nil {
+		return nil, err
+	}
+
+	l := &loader{fileValues: fileValues}
+
+	config := &Config{}
+	l.decode(reflect.ValueOf(config).Elem())
+	l.decodeOAuthProviders(&config.OAuth)
+
+	if len(l.problems) > 0 {
+		return nil, &ValidationError{Problems: l.problems}
+	}
+
+	return config, nil
+}
+
+type loader struct {
+	fileValues map[string]string
+	problems   []string
+}
+
+// lookup returns the raw value of key and whether it was set anywhere.
+func (l *loader) lookup(key string) (string, bool) {
+	if value, ok := os.LookupEnv(key); ok {
+		return value, true
+	}
+	if path, ok := os.LookupEnv(key + fileSuffix); ok {
+		return l.readSecret(key, path)
+	}
+	if value, ok := l.fileValues[key]; ok {
+		return value, true
+	}
+	if path, ok := l.fileValues[key+fileSuffix]; ok {
+		return l.readSecret(key, path)
+	}
+
+	return "", false
+}
+
+func (l *loader) readSecret(key string, path string) (string, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		l.problems = append(l.problems, fmt.Sprintf("%s%s: %s", key, fileSuffix, err))
+		return "", false
+	}
+
+	return strings.TrimRight(string(content), "\r\n"), true
+}
+
+func (l *loader) decode(value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+
+		key := field.Tag.Get("env")
+		if key == "" {
+			if field.Type.Kind() == reflect.Struct {
+				l.decode(value.Field(i))
+			}
+			continue
+		}
+
+		raw, ok := l.lookup(key)
+		if !ok {
+			raw, ok = field.Tag.Lookup("default")
+		}
+		if !ok {
+			continue
+		}
+
+		if err := setField(value.Field(i), field, strings.TrimSpace(raw)); err != nil {
+			l.problems = append(l.problems, fmt.Sprintf("%s: %s", key, err))
+		}
+	}
+}
+
+func (l *loader) decodeOAuthProviders(config *OAuth) {
+	names, _ := l.lookup(keyOAuthProviders)
+
+	for _, name := range splitList(names, ",") {
+		key := strings.ToUpper(name)
+		get := func(format string) string {
+			value, _ := l.lookup(fmt.Sprintf(format, key))
+			return strings.TrimSpace(value)
+		}
+
+		config.Providers = append(config.Providers, OAuthProvider{
+			Name:         name,
+			IssuerURL:    get(keyOAuthIssuerURLFormat),
+			ClientID:     get(keyOAuthClientIDFormat),
+			ClientSecret: get(keyOAuthClientSecretFormat),
+			RedirectURL:  get(keyOAuthRedirectURLFormat),
+			Scopes:       splitList(get(keyOAuthScopesFormat), ","),
+		})
+	}
+}
+
+func setField(value reflect.Value, field reflect.StructField, raw string) error {
+	if value.Type() == durationType {
+		duration, err := parseDuration(raw, field.Tag.Get("unit"))
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(duration))
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		if raw == "" {
+			value.SetBool(false)
+			return nil
+		}
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		value.SetBool(parsed)
+	case reflect.Int:
+		if raw == "" {
+			value.SetInt(0)
+			return nil
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		value.SetInt(int64(parsed))
+	case reflect.Float64:
+		if raw == "" {
+			value.SetFloat(0)
+			return nil
+		}
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice:
+		value.Set(reflect.ValueOf(splitList(raw, ",")))
+	case reflect.Map:
+		pairs := map[string]string{}
+		for _, pair := range splitList(raw, ";") {
+			k, v, ok := strings.Cut(pair, ":")
+			if !ok {
+				return fmt.Errorf("invalid pair %q, expected key:value", pair)
+			}
+			pairs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+		value.Set(reflect.ValueOf(pairs))
+	default:
+		return fmt.Errorf("unsupported field type %s", value.Type())
+	}
+
+	return nil
+}
+
+// parseDuration accepts Go duration strings, and plain integers in unit so
+// values written before durations were supported keep their meaning.
+func parseDuration(raw string, unit string) (time.Duration, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	if n, err := strconv.Atoi(raw); err == nil {
+		switch unit {
+		case "h":
+			return time.Duration(n) * time.Hour, nil
+		case "s":
+			return time.Duration(n) * time.Second, nil
+		default:
+			return time.Duration(n) * time.Minute, nil
+		}
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+
+	return duration, nil
+}
+
+func splitList(raw string, separator string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// readConfigFile flattens a YAML or TOML file into env-style keys: nested
+// tables are joined with "_" and upper-cased, so db.host becomes DB_HOST, and
+// lists are joined with ",".
+func readConfigFile(path string) (map[string]string, error) {
+	values := map[string]string{}
+	if path == "" {
+		return values, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+
+	document := map[string]any{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	flatten(values, "", document)
+
+	return values, nil
+}
+
+func flatten(values map[string]string, prefix string, document map[string]any) {
+	for key, value := range document {
+		key = strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(values, key, value)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
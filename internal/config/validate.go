@@ -0,0 +1,229 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// minJWTSecretLength is enforced in production only.
+const minJWTSecretLength = 32
+
+// ValidationError lists every configuration problem found, so they can all
+// be fixed at once.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type problems []string
+
+func (p *problems) add(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p *problems) required(key string, value string) {
+	if value == "" {
+		p.add("%s is required", key)
+	}
+}
+
+func (p *problems) oneOf(key string, value string, allowed ...string) {
+	for _, option := range allowed {
+		if value == option {
+			return
+		}
+	}
+	p.add("%s must be one of %s, got %q", key, strings.Join(allowed, ", "), value)
+}
+
+func (p *problems) atLeast(key string, value time.Duration, min time.Duration) {
+	if value < min {
+		p.add("%s must be at least %s, got %s", key, min, value)
+	}
+}
+
+// date checks an optional YYYY-MM-DD date.
+func (p *problems) date(key string, value string) {
+	if value == "" {
+		return
+	}
+	if _, err := time.Parse(time.DateOnly, value); err != nil {
+		p.add("%s must be a YYYY-MM-DD date, got %q", key, value)
+	}
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+// Validate checks the loaded values and returns a *ValidationError listing
+// every problem.
+func (c *Config) Validate() error {
+	var p problems
+
+	p.oneOf("ENV", c.Env, EnvDevelopment, EnvStaging, EnvProduction)
+	c.Log.validate(&p)
+	c.Server.validate(&p)
+	c.API.validate(&p)
+	p.oneOf("DEFAULT_LANGUAGE", c.I18n.DefaultLanguage, "en", "id")
+
+	c.Database.validate(&p)
+	c.JWT.validate(&p, c.IsProduction())
+
+	p.oneOf("COOKIE_SAME_SITE", strings.ToLower(c.Cookie.SameSite), "lax", "strict", "none")
+	if strings.EqualFold(c.Cookie.SameSite, "none") && !c.Cookie.Secure {
+		p.add("COOKIE_SECURE must be true when COOKIE_SAME_SITE is none")
+	}
+	if c.IsProduction() && c.Cookie.Enabled && !c.Cookie.Secure {
+		p.add("COOKIE_SECURE must be true in production")
+	}
+	if c.IsProduction() && c.Log.DeliveryContent {
+		p.add("LOG_DELIVERY_CONTENT must be false in production")
+	}
+
+	p.oneOf("MAILER_DRIVER", c.Mailer.Driver, "log", "smtp")
+	if c.Mailer.Driver == "smtp" {
+		p.required("SMTP_HOST", c.Mailer.SMTPHost)
+		p.required("SMTP_PORT", c.Mailer.SMTPPort)
+		p.required("SMTP_FROM", c.Mailer.SMTPFrom)
+	}
+
+	p.oneOf("SMS_DRIVER", c.SMS.Driver, "log", "http")
+	if c.SMS.Driver == "http" {
+		p.required("SMS_GATEWAY_URL", c.SMS.GatewayURL)
+	}
+
+	// Lifetimes below are handed to services in whole minutes or hours
+	p.atLeast("MAGIC_LINK_DURATION", c.MagicLink.Duration, time.Minute)
+	p.atLeast("OTP_DURATION", c.OTP.Duration, time.Minute)
+	if c.OTP.MaxAttempts < 1 {
+		p.add("OTP_MAX_ATTEMPTS must be at least 1, got %d", c.OTP.MaxAttempts)
+	}
+	if c.OTP.RequestLimit < 1 {
+		p.add("OTP_REQUEST_LIMIT must be at least 1, got %d", c.OTP.RequestLimit)
+	}
+	p.atLeast("OTP_REQUEST_WINDOW", c.OTP.RequestWindow, time.Minute)
+
+	if c.WebAuthn.Enabled() {
+		p.required("WEBAUTHN_RP_DISPLAY_NAME", c.WebAuthn.RPDisplayName)
+		if len(c.WebAuthn.RPOrigins) == 0 {
+			p.add("WEBAUTHN_RP_ORIGINS is required when WEBAUTHN_RP_ID is set")
+		}
+	}
+	p.atLeast("WEBAUTHN_CHALLENGE_DURATION", c.WebAuthn.ChallengeDuration, time.Minute)
+
+	p.atLeast("OAUTH_STATE_DURATION", c.OAuth.StateDuration, time.Minute)
+	for _, provider := range c.OAuth.Providers {
+		key := strings.ToUpper(provider.Name)
+		p.required(fmt.Sprintf(keyOAuthIssuerURLFormat, key), provider.IssuerURL)
+		p.required(fmt.Sprintf(keyOAuthClientIDFormat, key), provider.ClientID)
+		p.required(fmt.Sprintf(keyOAuthRedirectURLFormat, key), provider.RedirectURL)
+	}
+
+	p.oneOf("AUTH_DEFAULT_AUTHENTICATOR", c.LDAP.DefaultAuthenticator, "database", "ldap")
+	if c.LDAP.URL == "" {
+		if c.LDAP.DefaultAuthenticator == "ldap" || len(c.LDAP.Domains) > 0 {
+			p.add("LDAP_URL is required when AUTH_DEFAULT_AUTHENTICATOR is ldap or LDAP_DOMAINS is set")
+		}
+	} else {
+		p.required("LDAP_BASE_DN", c.LDAP.BaseDN)
+	}
+
+	p.atLeast("IMPERSONATION_TOKEN_DURATION", c.Impersonation.TokenDuration, time.Minute)
+	p.atLeast("ORG_INVITATION_DURATION", c.Organization.InvitationDuration, time.Hour)
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		p.add("TRACING_SAMPLE_RATIO must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+
+	return p.err()
+}
+
+func (l Log) validate(p *problems) {
+	p.oneOf("LOG_LEVEL", strings.ToLower(l.Level), "debug", "info", "warn", "error")
+	p.oneOf("LOG_FORMAT", l.Format, "text", "json")
+	for _, output := range l.Outputs {
+		p.oneOf("LOG_OUTPUTS", output, "stdout", "file")
+	}
+	if l.MaxSizeMB < 1 {
+		p.add("LOG_MAX_SIZE_MB must be at least 1, got %d", l.MaxSizeMB)
+	}
+	if l.MaxBackups < 0 {
+		p.add("LOG_MAX_BACKUPS must not be negative, got %d", l.MaxBackups)
+	}
+	p.atLeast("LOG_ROTATE_INTERVAL", l.RotateInterval, 0)
+	p.atLeast("LOG_MAX_AGE", l.MaxAge, 0)
+	if l.HashPII {
+		p.required("LOG_PII_HASH_KEY", l.PIIHashKey)
+	}
+}
+
+func (s Server) validate(p *problems) {
+	if _, err := strconv.Atoi(s.Port); err != nil {
+		p.add("PORT must be a number, got %q", s.Port)
+	}
+	if _, err := strconv.Atoi(s.MetricsPort); err != nil {
+		p.add("METRICS_PORT must be a number, got %q", s.MetricsPort)
+	} else if s.MetricsPort == s.Port {
+		p.add("METRICS_PORT must differ from PORT, got %q", s.MetricsPort)
+	}
+	p.atLeast("SERVER_READ_TIMEOUT", s.ReadTimeout, 0)
+	p.atLeast("SERVER_WRITE_TIMEOUT", s.WriteTimeout, 0)
+	p.atLeast("SERVER_IDLE_TIMEOUT", s.IdleTimeout, 0)
+	p.atLeast("SERVER_DRAIN_DELAY", s.DrainDelay, 0)
+	p.atLeast("SERVER_SHUTDOWN_TIMEOUT", s.ShutdownTimeout, time.Second)
+	p.oneOf("ERROR_FORMAT", s.ErrorFormat, ErrorFormatEnvelope, ErrorFormatProblem)
+}
+
+func (a API) validate(p *problems) {
+	p.date("API_UNVERSIONED_DEPRECATION", a.UnversionedDeprecation)
+	p.date("API_UNVERSIONED_SUNSET", a.UnversionedSunset)
+
+	deprecation, sunset := a.UnversionedDeprecationDate(), a.UnversionedSunsetDate()
+	if !sunset.IsZero() && sunset.Before(deprecation) {
+		p.add("API_UNVERSIONED_SUNSET must not be before API_UNVERSIONED_DEPRECATION, got %s", a.UnversionedSunset)
+	}
+}
+
+// Validate checks only the database settings, for commands such as migrate
+// that do not start the server.
+func (d Database) Validate() error {
+	var p problems
+	d.validate(&p)
+	return p.err()
+}
+
+func (d Database) validate(p *problems) {
+	p.required("DB_HOST", d.Host)
+	p.required("DB_USER", d.User)
+	p.required("DB_NAME", d.Name)
+	if _, err := strconv.Atoi(d.Port); err != nil {
+		p.add("DB_PORT must be a number, got %q", d.Port)
+	}
+	if d.TimeZone != "" {
+		if _, err := time.LoadLocation(d.TimeZone); err != nil {
+			p.add("TIMEZONE is not a valid time zone: %q", d.TimeZone)
+		}
+	}
+}
+
+func (j JWT) validate(p *problems, production bool) {
+	p.required("JWT_SECRET", j.Secret)
+	if production && j.Secret != "" && len(j.Secret) < minJWTSecretLength {
+		p.add("JWT_SECRET must be at least %d characters in production", minJWTSecretLength)
+	}
+
+	p.atLeast("ACCESS_TOKEN_DURATION", j.AccessTokenDuration, time.Minute)
+	p.atLeast("REFRESH_TOKEN_DURATION", j.RefreshTokenDuration, time.Minute)
+	if j.RefreshTokenDuration < j.AccessTokenDuration {
+		p.add("REFRESH_TOKEN_DURATION must not be shorter than ACCESS_TOKEN_DURATION")
+	}
+}
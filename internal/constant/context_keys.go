@@ -1,5 +1,13 @@
 package constant
 
 const (
-	ContextKeyUser string = "user_ctx"
+	ContextKeyUser       string = "user_ctx"
+	ContextKeyActor      string = "actor_ctx"
+	ContextKeyMembership string = "membership_ctx"
+)
+
+// Keys of request scoped values attached to logs with logger.WithValue.
+const (
+	LogKeyRequestID string = "requestId"
+	LogKeyUserID    string = "userId"
 )
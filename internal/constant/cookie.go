@@ -0,0 +1,12 @@
+package constant
+
+const (
+	CookieAccessToken  string = "access-token"
+	CookieRefreshToken string = "refresh-token"
+	CookieCSRFToken    string = "XSRF-TOKEN"
+	CookieMagicLink    string = "magic-link-nonce"
+	CookieWebAuthn     string = "webauthn-session"
+	CookieOAuthState   string = "oauth-state"
+
+	HeaderCSRFToken string = "X-XSRF-TOKEN"
+)
@@ -1,8 +0,0 @@
-package constant
-
-var (
-	EnvKeyRefreshTokenDuration = "REFRESH_TOKEN_DURATION"
-	EnvKeyAccessTokenDuration  = "ACCESS_TOKEN_DURATION"
-	EnvKeyJWTSecret            = "JWT_SECRET"
-	EnvKeyAppName              = "APP_NAME"
-)
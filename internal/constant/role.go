@@ -0,0 +1,18 @@
+package constant
+
+const (
+	RoleAdmin string = "admin"
+)
+
+// Scopes granted to impersonation tokens. Regular tokens are not scoped.
+const (
+	ScopeRead  string = "read"
+	ScopeWrite string = "write"
+)
+
+// Roles of a user inside an organization.
+const (
+	OrganizationRoleOwner  string = "owner"
+	OrganizationRoleAdmin  string = "admin"
+	OrganizationRoleMember string = "member"
+)
@@ -0,0 +1,12 @@
+package dto
+
+type ImpersonateBody struct {
+	Reason string   `json:"reason" binding:"required"`
+	Scopes []string `json:"scopes"`
+}
+
+type ImpersonateResponse struct {
+	AccesToken string   `json:"access_token"`
+	ExpiresIn  int      `json:"expires_in"`
+	Scopes     []string `json:"scopes"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+type LogLevelBody struct {
+	Level string `json:"level" binding:"required"`
+}
+
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
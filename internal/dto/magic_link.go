@@ -0,0 +1,5 @@
+package dto
+
+type MagicLinkBody struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+type CreateOrganizationBody struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type SwitchOrganizationBody struct {
+	OrganizationId string `json:"organization_id" binding:"required,uuid"`
+}
+
+type InviteMemberBody struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role"`
+}
+
+type AcceptInvitationBody struct {
+	Token string `json:"token" binding:"required"`
+}
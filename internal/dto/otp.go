@@ -0,0 +1,10 @@
+package dto
+
+type OTPRequestBody struct {
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+}
+
+type OTPVerifyBody struct {
+	PhoneNumber string `json:"phone_number" binding:"required,phone"`
+	Code        string `json:"code" binding:"required,numeric"`
+}
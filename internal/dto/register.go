@@ -2,11 +2,20 @@ package dto
 
 import "github.com/google/uuid"
 
+// RegisterBody is the register body of the unversioned API, which accepts
+// any username as it did before the username rules were added.
 type RegisterBody struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
+// RegisterBodyV1 is the register body of /v1, where usernames follow the
+// username rules.
+type RegisterBodyV1 struct {
+	Username string `json:"username" binding:"required,min=3,max=32,username"`
+	Password string `json:"password" binding:"required"`
+}
+
 type RegisterResponse struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
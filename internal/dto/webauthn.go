@@ -0,0 +1,6 @@
+package dto
+
+type WebAuthnLoginBeginBody struct {
+	// Username is optional; without it a discoverable (passkey) login is started.
+	Username string `json:"username"`
+}
@@ -0,0 +1,76 @@
+package errs
+
+import "errors"
+
+const CodeInternal = "INTERNAL_ERROR"
+
+// AppError is an error clients can act on: Code is stable and documented,
+// Status is the HTTP status it is served with and Message is safe to show.
+// middleware.ErrorHandler turns it into the response; any other error is
+// served as a 500 with a generic message.
+type AppError struct {
+	Code    string
+	Status  int
+	Message string
+	// Details is extra data for the client, e.g. the fields that failed
+	// validation.
+	Details interface{}
+
+	cause error
+}
+
+func New(code string, status int, message string) *AppError {
+	return &AppError{
+		Code:    code,
+		Status:  status,
+		Message: message,
+	}
+}
+
+func (e *AppError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.cause
+}
+
+// Is matches any AppError with the same code, so the copies returned by
+// WithDetails and Wrap still match the sentinel they were made from.
+func (e *AppError) Is(target error) bool {
+	appErr, ok := target.(*AppError)
+
+	return ok && appErr.Code == e.Code
+}
+
+// WithDetails returns a copy of e carrying details.
+func (e *AppError) WithDetails(details interface{}) *AppError {
+	copied := *e
+	copied.Details = details
+
+	return &copied
+}
+
+// Wrap returns a copy of e caused by err. err is logged but never shown to
+// the client.
+func (e *AppError) Wrap(err error) *AppError {
+	copied := *e
+	copied.cause = err
+
+	return &copied
+}
+
+// AsAppError returns the AppError in err's chain, or an INTERNAL_ERROR
+// wrapping err when there is none.
+func AsAppError(err error) *AppError {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return Internal.Wrap(err)
+}
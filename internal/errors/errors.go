@@ -1,26 +1,76 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+)
 
 var (
-	InvalidBearerFormat = errors.New("Invalid Authorization Bearer Format")
-	InvalidToken        = errors.New("Invalid Token")
-	InvalidIssuer       = errors.New("Invalid Token Issuer")
-	InvalidIDParam      = errors.New("Invalid ID Parameter")
+	Internal = New(CodeInternal, http.StatusInternalServerError, "Something went wrong")
+
+	InvalidBearerFormat = New("AUTH_INVALID_BEARER_FORMAT", http.StatusUnauthorized, "Invalid Authorization Bearer Format")
+	InvalidToken        = New("AUTH_INVALID_TOKEN", http.StatusUnauthorized, "Invalid Token")
+	InvalidIssuer       = New("AUTH_INVALID_ISSUER", http.StatusUnauthorized, "Invalid Token Issuer")
+	InvalidIDParam      = New("REQUEST_INVALID_ID", http.StatusBadRequest, "Invalid ID Parameter")
+	InvalidCSRFToken    = New("AUTH_INVALID_CSRF_TOKEN", http.StatusForbidden, "Invalid CSRF Token")
+	SessionRevoked      = New("AUTH_SESSION_REVOKED", http.StatusUnauthorized, "Session has been revoked")
 
-	ForbiddenAccess = errors.New("user is forbidden to access this resource")
+	ForbiddenAccess   = New("AUTH_FORBIDDEN", http.StatusForbidden, "user is forbidden to access this resource")
+	InsufficientScope = New("AUTH_INSUFFICIENT_SCOPE", http.StatusForbidden, "token scope does not allow this request")
 
-	InvalidRequestBody = errors.New("invalid request body")
+	RouteNotFound      = New("REQUEST_ROUTE_NOT_FOUND", http.StatusNotFound, "route not found")
+	InvalidRequestBody = New("REQUEST_INVALID_BODY", http.StatusBadRequest, "invalid request body")
+	ValidationFailed   = New("REQUEST_VALIDATION_FAILED", http.StatusBadRequest, "request validation failed")
+	InvalidLogLevel    = New("REQUEST_INVALID_LOG_LEVEL", http.StatusBadRequest, "invalid log level")
 
-	EmailAlreadyUsed           = errors.New("email already used")
-	UsernameAlreadyUsed        = errors.New("username already used")
-	PasswordDoesntMatch        = errors.New("password doesn't match")
-	PasswordContainUsername    = errors.New("password must not contain username")
-	PasswordSameAsBefore       = errors.New("Password cannot be same as before")
-	UsernamePasswordIncorrect  = errors.New("username or password incorrect")
+	EmailAlreadyUsed           = New("USER_EMAIL_TAKEN", http.StatusBadRequest, "email already used")
+	UsernameAlreadyUsed        = New("USER_USERNAME_TAKEN", http.StatusBadRequest, "username already used")
+	PasswordDoesntMatch        = New("AUTH_PASSWORD_MISMATCH", http.StatusUnauthorized, "password doesn't match")
+	PasswordContainUsername    = New("USER_PASSWORD_CONTAINS_USERNAME", http.StatusBadRequest, "password must not contain username")
+	PasswordSameAsBefore       = New("USER_PASSWORD_REUSED", http.StatusBadRequest, "Password cannot be same as before")
+	UsernamePasswordIncorrect  = New("AUTH_INVALID_CREDENTIALS", http.StatusUnauthorized, "username or password incorrect")
 	SearchUsernameError        = errors.New("Error occurred while searching for username")
 	CheckPasswordError         = errors.New("Error occurred while checking for password")
 	GenerateLoginResponseError = errors.New("Error occurred while generating login response")
+	SearchEmailError           = errors.New("Error occurred while searching for email")
+	AccountDeactivated         = New("AUTH_ACCOUNT_DEACTIVATED", http.StatusForbidden, "account has been deactivated")
+	DirectoryAccountNotLinked  = New("AUTH_DIRECTORY_ACCOUNT_NOT_LINKED", http.StatusConflict, "a local account with this username exists and is not linked to the directory")
+	DirectoryUnavailable       = New("AUTH_DIRECTORY_UNAVAILABLE", http.StatusServiceUnavailable, "Error occurred while contacting the directory")
+
+	InvalidMagicLink   = New("AUTH_INVALID_MAGIC_LINK", http.StatusUnauthorized, "magic link is invalid or has expired")
+	SendMagicLinkError = New("AUTH_MAGIC_LINK_NOT_SENT", http.StatusBadGateway, "Error occurred while sending magic link")
+
+	InvalidOTP          = New("AUTH_INVALID_OTP", http.StatusUnauthorized, "OTP code is invalid or has expired")
+	OTPAttemptsExceeded = New("AUTH_OTP_ATTEMPTS_EXCEEDED", http.StatusTooManyRequests, "too many OTP attempts, request a new code")
+	OTPRequestsExceeded = New("AUTH_OTP_REQUESTS_EXCEEDED", http.StatusTooManyRequests, "too many OTP codes requested, try again later")
+	SendOTPError        = New("AUTH_OTP_NOT_SENT", http.StatusBadGateway, "Error occurred while sending OTP code")
+
+	InvalidWebAuthnSession     = New("WEBAUTHN_INVALID_SESSION", http.StatusBadRequest, "WebAuthn session is invalid or has expired")
+	WebAuthnNoCredentials      = New("WEBAUTHN_NO_CREDENTIALS", http.StatusBadRequest, "no passkey registered for this account")
+	WebAuthnVerificationFailed = New("WEBAUTHN_VERIFICATION_FAILED", http.StatusUnauthorized, "WebAuthn verification failed")
+	WebAuthnDisabled           = New("WEBAUTHN_DISABLED", http.StatusNotFound, "passkeys are not enabled")
+	WebAuthnCloneDetected      = New("WEBAUTHN_CLONE_DETECTED", http.StatusUnauthorized, "authenticator may be cloned, passkey login rejected")
+
+	OAuthProviderNotFound    = New("OAUTH_PROVIDER_NOT_FOUND", http.StatusNotFound, "identity provider not found")
+	OAuthProviderUnavailable = New("OAUTH_PROVIDER_UNAVAILABLE", http.StatusBadGateway, "identity provider is unavailable")
+	InvalidOAuthState        = New("OAUTH_INVALID_STATE", http.StatusUnauthorized, "OAuth state is invalid or has expired")
+	OAuthExchangeFailed      = New("OAUTH_EXCHANGE_FAILED", http.StatusUnauthorized, "failed to exchange authorization code")
+
+	ScimResourceNotFound = New("SCIM_RESOURCE_NOT_FOUND", http.StatusNotFound, "resource not found")
+	ScimInvalidFilter    = New("SCIM_INVALID_FILTER", http.StatusBadRequest, "invalid or unsupported filter")
+	ScimInvalidPatch     = New("SCIM_INVALID_PATCH", http.StatusBadRequest, "invalid patch operation")
+	ScimInvalidValue     = New("SCIM_INVALID_VALUE", http.StatusBadRequest, "invalid attribute value")
+	RoleAlreadyExists    = New("ROLE_ALREADY_EXISTS", http.StatusConflict, "group already exists")
+
+	OrganizationRequired    = New("ORG_REQUIRED", http.StatusForbidden, "an active organization is required, switch organization first")
+	OrganizationNotMember   = New("ORG_NOT_MEMBER", http.StatusForbidden, "user is not a member of this organization")
+	InvalidInvitation       = New("ORG_INVALID_INVITATION", http.StatusBadRequest, "invitation is invalid or has expired")
+	SendInvitationError     = New("ORG_INVITATION_NOT_SENT", http.StatusBadGateway, "Error occurred while sending invitation")
+	InvalidOrganizationRole = New("ORG_INVALID_ROLE", http.StatusBadRequest, "invalid organization role")
+
+	ImpersonationTargetNotFound = New("IMPERSONATION_TARGET_NOT_FOUND", http.StatusNotFound, "user to impersonate not found")
+	ImpersonationNotAllowed     = New("IMPERSONATION_NOT_ALLOWED", http.StatusForbidden, "user cannot be impersonated")
+	InvalidImpersonationScope   = New("IMPERSONATION_INVALID_SCOPE", http.StatusBadRequest, "invalid impersonation scope")
 
 	ParseUUIDError = errors.New("Error parsing UUID")
 )
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/audit"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	impersonationService service.ImpersonationService
+}
+
+type AdminHandlerConfig struct {
+	ImpersonationService service.ImpersonationService
+}
+
+func NewAdminHandler(config AdminHandlerConfig) *AdminHandler {
+	return &AdminHandler{
+		impersonationService: config.ImpersonationService,
+	}
+}
+
+func (h *AdminHandler) Impersonate(c *gin.Context) {
+	value, _ := c.Get(constant.ContextKeyUser)
+	actor, ok := value.(tokenprovider.UserClaims)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	userId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		response.Fail(c, errs.InvalidIDParam)
+		return
+	}
+
+	var impersonateBody dto.ImpersonateBody
+
+	if err := c.ShouldBindJSON(&impersonateBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.impersonationService.Impersonate(c.Request.Context(), actor, userId, &impersonateBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Impersonation token issued", resp)
+}
+
+func (h *AdminHandler) GetLogLevel(c *gin.Context) {
+	response.JSON(c, 200, "Log level", dto.LogLevelResponse{Level: logger.Level()})
+}
+
+func (h *AdminHandler) SetLogLevel(c *gin.Context) {
+	value, _ := c.Get(constant.ContextKeyUser)
+	actor, ok := value.(tokenprovider.UserClaims)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	var logLevelBody dto.LogLevelBody
+
+	if err := c.ShouldBindJSON(&logLevelBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	previous := logger.Level()
+
+	if err := logger.SetLevel(logLevelBody.Level); err != nil {
+		if errors.Is(err, logger.ErrInvalidLevel) {
+			err = errs.InvalidLogLevel
+		}
+		response.Fail(c, err)
+		return
+	}
+
+	// Audit entries are kept whatever the new level is
+	audit.Log(c.Request.Context(), "log level changed", map[string]string{
+		"actorId":  actor.UserID,
+		"previous": previous,
+		"level":    logger.Level(),
+	})
+
+	response.JSON(c, 200, "Log level updated", dto.LogLevelResponse{Level: logger.Level()})
+}
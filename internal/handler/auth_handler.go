@@ -2,14 +2,17 @@ package handler
 
 import (
 	"errors"
-	"net/http"
 
+	"github.com/EputraP/kfc_be/internal/constant"
 	"github.com/EputraP/kfc_be/internal/dto"
 	errs "github.com/EputraP/kfc_be/internal/errors"
 	"github.com/EputraP/kfc_be/internal/service"
-	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
 	"github.com/EputraP/kfc_be/internal/util/response"
 	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/validation"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -17,44 +20,50 @@ import (
 type AuthHandler struct {
 	authService   service.AuthService
 	tokenProvider tokenprovider.JWTTokenProvider
+	cookie        cookie.Config
 }
 
 type AuthHandlerConfig struct {
 	AuthService   service.AuthService
 	TokenProvider tokenprovider.JWTTokenProvider
+	Cookie        cookie.Config
 }
 
 func NewAuthHandler(config AuthHandlerConfig) *AuthHandler {
 	return &AuthHandler{
 		authService:   config.AuthService,
 		tokenProvider: config.TokenProvider,
+		cookie:        config.Cookie,
 	}
 }
 
 func (h *AuthHandler) CreateUser(c *gin.Context) {
 	var registerBody dto.RegisterBody
 
-	if err := c.ShouldBindJSON(&registerBody); err != nil {
-		response.Error(c, 400, errs.InvalidRequestBody.Error())
-		return
+	// Unversioned clients keep the username rules they were released with
+	if apiversion.FromContext(c.Request.Context()) == apiversion.Unversioned {
+		if err := c.ShouldBindJSON(&registerBody); err != nil {
+			response.Fail(c, validation.Error(err))
+			return
+		}
+	} else {
+		var registerBodyV1 dto.RegisterBodyV1
+		if err := c.ShouldBindJSON(&registerBodyV1); err != nil {
+			response.Fail(c, validation.Error(err))
+			return
+		}
+		registerBody = dto.RegisterBody(registerBodyV1)
 	}
 
-	resp, err := h.authService.CreateUser(&registerBody)
+	resp, err := h.authService.CreateUser(c.Request.Context(), &registerBody)
 
 	if err != nil {
-		if errors.Is(err, errs.UsernameAlreadyUsed) ||
-			errors.Is(err, errs.PasswordContainUsername) {
-			response.Error(c, 400, err.Error())
-			return
-		}
-		logger.Error("AuthHandler CreateUser", "Failed to create user", map[string]string{
-			"error": err.Error(),
-		})
-
-		response.UnknownError(c, err)
+		response.Fail(c, err)
 		return
 	}
 
+	metrics.Registrations.Inc()
+
 	response.JSON(c, 201, "Register Success", resp)
 }
 
@@ -62,35 +71,32 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	var loginBody dto.LoginBody
 
 	if err := c.ShouldBindJSON(&loginBody); err != nil {
-		response.Error(c, 400, errs.InvalidRequestBody.Error())
+		response.Fail(c, validation.Error(err))
 		return
 	}
 
-	resp, err := h.authService.Login(&loginBody)
+	resp, err := h.authService.Login(c.Request.Context(), &loginBody)
+	metrics.ObserveLogin(metrics.LoginMethodPassword, err)
 	if err != nil {
+		// Do not tell which of username or password was wrong
 		if errors.Is(err, errs.PasswordDoesntMatch) ||
 			errors.Is(err, gorm.ErrRecordNotFound) {
-			response.Error(c, 401, errs.UsernamePasswordIncorrect.Error())
-			return
+			err = errs.UsernamePasswordIncorrect
 		}
-		logger.Error("AuthHandler Login", "Failed to login", map[string]string{
-			"error": err.Error(),
-		})
-
-		response.UnknownError(c, err)
+		response.Fail(c, err)
 		return
 	}
 
-	c.SetSameSite(http.SameSiteLaxMode)
-	c.SetCookie("refresh-token", resp.RefreshToken, 3600*24*30, "", "", true, true)
-	c.SetCookie("access-token", resp.AccesToken, 3600*24*30, "", "/", true, true)
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
 
 	response.JSON(c, 200, "Login success", resp)
 }
 
 func (h *AuthHandler) Logout(c *gin.Context) {
-	c.SetCookie("refresh-token", "", -1, "", "", true, true)
-	c.SetCookie("access-token", "", -1, "", "/", true, true)
+	cookie.Clear(c, h.cookie)
 
 	response.JSON(c, 200, "Logout success", nil)
 }
@@ -98,23 +104,38 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	authHeader := c.Request.Header.Get("Authorization")
 
-	refreshToken, err := h.tokenProvider.ExtractToken(authHeader)
+	var refreshToken string
+	var err error
+
+	if authHeader == "" && h.cookie.Enabled {
+		refreshToken, err = c.Cookie(constant.CookieRefreshToken)
+		if err != nil {
+			err = errs.InvalidToken.Wrap(err)
+		}
+	} else {
+		refreshToken, err = h.tokenProvider.ExtractToken(authHeader)
+	}
 	if err != nil {
-		logger.Error("AuthHandler Refresh", "Failed to extract refresh token", map[string]string{
-			"error": err.Error(),
-		})
-		response.Error(c, 400, err.Error())
+		response.Fail(c, err)
 		return
 	}
 
-	token, err := h.tokenProvider.RenewAccessToken(refreshToken)
+	token, err := h.authService.RenewAccessToken(c.Request.Context(), refreshToken)
+	metrics.ObserveRefresh(err)
 	if err != nil {
-		logger.Error("AuthHandler Refresh", "Failed to renew access token", map[string]string{
-			"error": err.Error(),
-		})
-		response.Error(c, 400, err.Error())
+		response.Fail(c, err)
+		return
+	}
+
+	if h.cookie.Enabled {
+		cookie.SetAccessToken(c, h.cookie, *token)
+	}
+
+	// Unversioned clients expect the bare token
+	if apiversion.FromContext(c.Request.Context()) == apiversion.Unversioned {
+		response.JSON(c, 200, "Renew Access Token Success", *token)
 		return
 	}
 
-	response.JSON(c, 200, "Renew Access Token Success", *token)
+	response.JSON(c, 200, "Renew Access Token Success", dto.RefreshTokenResponse{AccesToken: *token})
 }
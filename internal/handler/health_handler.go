@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/EputraP/kfc_be/internal/dto"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+type HealthHandlerConfig struct {
+	HealthService service.HealthService
+}
+
+func NewHealthHandler(config HealthHandlerConfig) *HealthHandler {
+	return &HealthHandler{
+		healthService: config.HealthService,
+	}
+}
+
+func (h *HealthHandler) Live(c *gin.Context) {
+	response.JSON(c, 200, "Alive", h.healthService.Live(c.Request.Context()))
+}
+
+func (h *HealthHandler) Ready(c *gin.Context) {
+	resp := h.healthService.Ready(c.Request.Context())
+	if resp.Status != dto.HealthStatusUp {
+		response.JSON(c, 503, "Not ready", resp)
+		return
+	}
+
+	response.JSON(c, 200, "Ready", resp)
+}
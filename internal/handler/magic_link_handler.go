@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService service.MagicLinkService
+	cookie           cookie.Config
+	linkDuration     int
+}
+
+type MagicLinkHandlerConfig struct {
+	MagicLinkService service.MagicLinkService
+	Cookie           cookie.Config
+	// LinkDuration is the lifetime of a link in minutes, used for the nonce cookie.
+	LinkDuration int
+}
+
+func NewMagicLinkHandler(config MagicLinkHandlerConfig) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: config.MagicLinkService,
+		cookie:           config.Cookie,
+		linkDuration:     config.LinkDuration,
+	}
+}
+
+func (h *MagicLinkHandler) Request(c *gin.Context) {
+	var magicLinkBody dto.MagicLinkBody
+
+	if err := c.ShouldBindJSON(&magicLinkBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	nonce, err := h.magicLinkService.RequestMagicLink(c.Request.Context(), &magicLinkBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieMagicLink, nonce, h.linkDuration*60)
+
+	response.JSON(c, 200, "If the email is registered, a sign-in link has been sent", nil)
+}
+
+func (h *MagicLinkHandler) Callback(c *gin.Context) {
+	token := c.Query("token")
+	nonce, _ := c.Cookie(constant.CookieMagicLink)
+
+	resp, err := h.magicLinkService.VerifyMagicLink(c.Request.Context(), token, nonce)
+	metrics.ObserveLogin(metrics.LoginMethodMagicLink, err)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieMagicLink, "", -1)
+
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Login success", resp)
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService  service.OAuthService
+	cookie        cookie.Config
+	stateDuration int
+}
+
+type OAuthHandlerConfig struct {
+	OAuthService service.OAuthService
+	Cookie       cookie.Config
+	// StateDuration is the lifetime of an authorization request in minutes, used for the state cookie.
+	StateDuration int
+}
+
+func NewOAuthHandler(config OAuthHandlerConfig) *OAuthHandler {
+	return &OAuthHandler{
+		oauthService:  config.OAuthService,
+		cookie:        config.Cookie,
+		stateDuration: config.StateDuration,
+	}
+}
+
+func (h *OAuthHandler) Start(c *gin.Context) {
+	authURL, state, err := h.oauthService.Start(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieOAuthState, state, h.stateDuration*60)
+
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *OAuthHandler) Callback(c *gin.Context) {
+	expectedState, _ := c.Cookie(constant.CookieOAuthState)
+	cookie.Set(c, h.cookie, constant.CookieOAuthState, "", -1)
+
+	if providerError := c.Query("error"); providerError != "" {
+		logger.WarnContext(c.Request.Context(), "OAuthHandler Callback", "Identity provider returned an error", map[string]string{
+			"error":       providerError,
+			"description": c.Query("error_description"),
+		})
+		response.Fail(c, errs.OAuthExchangeFailed)
+		return
+	}
+
+	resp, err := h.oauthService.Callback(c.Request.Context(), c.Param("provider"), c.Query("state"), expectedState, c.Query("code"))
+	metrics.ObserveLogin(metrics.LoginMethodOAuth, err)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Login success", resp)
+}
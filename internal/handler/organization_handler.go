@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	organizationService service.OrganizationService
+	cookie              cookie.Config
+}
+
+type OrganizationHandlerConfig struct {
+	OrganizationService service.OrganizationService
+	Cookie              cookie.Config
+}
+
+func NewOrganizationHandler(config OrganizationHandlerConfig) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: config.OrganizationService,
+		cookie:              config.Cookie,
+	}
+}
+
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	var createOrganizationBody dto.CreateOrganizationBody
+
+	if err := c.ShouldBindJSON(&createOrganizationBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.organizationService.CreateOrganization(c.Request.Context(), userId, &createOrganizationBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 201, "Organization created", resp)
+}
+
+func (h *OrganizationHandler) List(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	resp, err := h.organizationService.ListOrganizations(c.Request.Context(), userId)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Organizations found", resp)
+}
+
+func (h *OrganizationHandler) Switch(c *gin.Context) {
+	value, _ := c.Get(constant.ContextKeyUser)
+	claims, ok := value.(tokenprovider.UserClaims)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	var switchOrganizationBody dto.SwitchOrganizationBody
+
+	if err := c.ShouldBindJSON(&switchOrganizationBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.organizationService.SwitchOrganization(c.Request.Context(), claims, uuid.MustParse(switchOrganizationBody.OrganizationId))
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Organization switched", resp)
+}
+
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	membership, ok := currentMembership(c)
+	if !ok {
+		response.Fail(c, errs.OrganizationRequired)
+		return
+	}
+
+	resp, err := h.organizationService.ListMembers(c.Request.Context(), membership.OrganizationId)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Members found", resp)
+}
+
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	membership, ok := currentMembership(c)
+	if !ok {
+		response.Fail(c, errs.OrganizationRequired)
+		return
+	}
+
+	var inviteMemberBody dto.InviteMemberBody
+
+	if err := c.ShouldBindJSON(&inviteMemberBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.organizationService.InviteMember(c.Request.Context(), membership, &inviteMemberBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 201, "Invitation sent", resp)
+}
+
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	var acceptInvitationBody dto.AcceptInvitationBody
+
+	if err := c.ShouldBindJSON(&acceptInvitationBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.organizationService.AcceptInvitation(c.Request.Context(), userId, &acceptInvitationBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Invitation accepted", resp)
+}
+
+func currentMembership(c *gin.Context) (*model.Membership, bool) {
+	value, ok := c.Get(constant.ContextKeyMembership)
+	if !ok {
+		return nil, false
+	}
+
+	membership, ok := value.(*model.Membership)
+
+	return membership, ok
+}
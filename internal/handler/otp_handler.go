@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin"
+)
+
+type OTPHandler struct {
+	otpService service.OTPService
+	cookie     cookie.Config
+}
+
+type OTPHandlerConfig struct {
+	OTPService service.OTPService
+	Cookie     cookie.Config
+}
+
+func NewOTPHandler(config OTPHandlerConfig) *OTPHandler {
+	return &OTPHandler{
+		otpService: config.OTPService,
+		cookie:     config.Cookie,
+	}
+}
+
+func (h *OTPHandler) Request(c *gin.Context) {
+	var otpRequestBody dto.OTPRequestBody
+
+	if err := c.ShouldBindJSON(&otpRequestBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	if err := h.otpService.RequestOTP(c.Request.Context(), &otpRequestBody); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "OTP code sent", nil)
+}
+
+func (h *OTPHandler) Verify(c *gin.Context) {
+	var otpVerifyBody dto.OTPVerifyBody
+
+	if err := c.ShouldBindJSON(&otpVerifyBody); err != nil {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	resp, err := h.otpService.VerifyOTP(c.Request.Context(), &otpVerifyBody)
+	metrics.ObserveLogin(metrics.LoginMethodOTP, err)
+	if err != nil {
+		if errors.Is(err, errs.OTPAttemptsExceeded) {
+			metrics.Lockouts.WithLabelValues(metrics.LoginMethodOTP).Inc()
+		}
+		response.Fail(c, err)
+		return
+	}
+
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Login success", resp)
+}
@@ -0,0 +1,282 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/scim"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ScimHandler struct {
+	scimService service.ScimService
+}
+
+type ScimHandlerConfig struct {
+	ScimService service.ScimService
+}
+
+func NewScimHandler(config ScimHandlerConfig) *ScimHandler {
+	return &ScimHandler{
+		scimService: config.ScimService,
+	}
+}
+
+func (h *ScimHandler) ListUsers(c *gin.Context) {
+	startIndex, count := scimPagination(c)
+
+	resp, err := h.scimService.ListUsers(c.Request.Context(), c.Query("filter"), startIndex, count)
+	if err != nil {
+		h.error(c, "ScimHandler ListUsers", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) GetUser(c *gin.Context) {
+	userId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.scimService.GetUser(c.Request.Context(), userId)
+	if err != nil {
+		h.error(c, "ScimHandler GetUser", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) CreateUser(c *gin.Context) {
+	var body scim.User
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.CreateUser(c.Request.Context(), &body)
+	if err != nil {
+		h.error(c, "ScimHandler CreateUser", err)
+		return
+	}
+
+	c.Header("Location", resp.Meta.Location)
+	scim.JSON(c, 201, resp)
+}
+
+func (h *ScimHandler) ReplaceUser(c *gin.Context) {
+	userId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	var body scim.User
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.ReplaceUser(c.Request.Context(), userId, &body)
+	if err != nil {
+		h.error(c, "ScimHandler ReplaceUser", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) PatchUser(c *gin.Context) {
+	userId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	var body scim.PatchRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.PatchUser(c.Request.Context(), userId, &body)
+	if err != nil {
+		h.error(c, "ScimHandler PatchUser", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) DeleteUser(c *gin.Context) {
+	userId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scimService.DeleteUser(c.Request.Context(), userId); err != nil {
+		h.error(c, "ScimHandler DeleteUser", err)
+		return
+	}
+
+	c.Status(204)
+}
+
+func (h *ScimHandler) ListGroups(c *gin.Context) {
+	startIndex, count := scimPagination(c)
+
+	resp, err := h.scimService.ListGroups(c.Request.Context(), c.Query("filter"), startIndex, count)
+	if err != nil {
+		h.error(c, "ScimHandler ListGroups", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) GetGroup(c *gin.Context) {
+	roleId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.scimService.GetGroup(c.Request.Context(), roleId)
+	if err != nil {
+		h.error(c, "ScimHandler GetGroup", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) CreateGroup(c *gin.Context) {
+	var body scim.Group
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.CreateGroup(c.Request.Context(), &body)
+	if err != nil {
+		h.error(c, "ScimHandler CreateGroup", err)
+		return
+	}
+
+	c.Header("Location", resp.Meta.Location)
+	scim.JSON(c, 201, resp)
+}
+
+func (h *ScimHandler) ReplaceGroup(c *gin.Context) {
+	roleId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	var body scim.Group
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.ReplaceGroup(c.Request.Context(), roleId, &body)
+	if err != nil {
+		h.error(c, "ScimHandler ReplaceGroup", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) PatchGroup(c *gin.Context) {
+	roleId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	var body scim.PatchRequest
+
+	if err := c.ShouldBindJSON(&body); err != nil {
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+		return
+	}
+
+	resp, err := h.scimService.PatchGroup(c.Request.Context(), roleId, &body)
+	if err != nil {
+		h.error(c, "ScimHandler PatchGroup", err)
+		return
+	}
+
+	scim.JSON(c, 200, resp)
+}
+
+func (h *ScimHandler) DeleteGroup(c *gin.Context) {
+	roleId, ok := scimResourceId(c)
+	if !ok {
+		return
+	}
+
+	if err := h.scimService.DeleteGroup(c.Request.Context(), roleId); err != nil {
+		h.error(c, "ScimHandler DeleteGroup", err)
+		return
+	}
+
+	c.Status(204)
+}
+
+// error writes err as a SCIM error response, mapping known errors to their
+// status and scimType.
+func (h *ScimHandler) error(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, errs.ScimResourceNotFound):
+		scim.ErrorResponse(c, 404, "", err.Error())
+	case errors.Is(err, errs.ScimInvalidFilter):
+		scim.ErrorResponse(c, 400, "invalidFilter", err.Error())
+	case errors.Is(err, errs.ScimInvalidPatch):
+		scim.ErrorResponse(c, 400, "invalidSyntax", err.Error())
+	case errors.Is(err, errs.ScimInvalidValue):
+		scim.ErrorResponse(c, 400, "invalidValue", err.Error())
+	case errors.Is(err, errs.UsernameAlreadyUsed) ||
+		errors.Is(err, errs.RoleAlreadyExists):
+		scim.ErrorResponse(c, 409, "uniqueness", err.Error())
+	default:
+		logger.ErrorContext(c.Request.Context(), msg, "Failed to process SCIM request", map[string]string{
+			"error": err.Error(),
+		})
+		scim.ErrorResponse(c, 500, "", "Something went wrong")
+	}
+}
+
+func scimResourceId(c *gin.Context) (uuid.UUID, bool) {
+	resourceId, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		scim.ErrorResponse(c, 404, "", errs.ScimResourceNotFound.Error())
+		return uuid.Nil, false
+	}
+
+	return resourceId, true
+}
+
+// scimPagination reads startIndex and count. A count left out is passed as
+// -1 so the service applies its default, while count=0 asks for totalResults
+// only and a negative count is read as 0 (RFC 7644 section 3.4.2.4).
+func scimPagination(c *gin.Context) (int, int) {
+	startIndex, _ := strconv.Atoi(c.Query("startIndex"))
+
+	count, err := strconv.Atoi(c.Query("count"))
+	if err != nil {
+		count = -1
+	} else if count < 0 {
+		count = 0
+	}
+
+	return startIndex, count
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+)
+
+type WebAuthnHandler struct {
+	webAuthnService   service.WebAuthnService
+	cookie            cookie.Config
+	challengeDuration int
+}
+
+type WebAuthnHandlerConfig struct {
+	WebAuthnService service.WebAuthnService
+	Cookie          cookie.Config
+	// ChallengeDuration is the lifetime of a ceremony in minutes, used for the session cookie.
+	ChallengeDuration int
+}
+
+func NewWebAuthnHandler(config WebAuthnHandlerConfig) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webAuthnService:   config.WebAuthnService,
+		cookie:            config.Cookie,
+		challengeDuration: config.ChallengeDuration,
+	}
+}
+
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	options, sessionId, err := h.webAuthnService.BeginRegistration(c.Request.Context(), userId)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieWebAuthn, sessionId.String(), h.challengeDuration*60)
+
+	response.JSON(c, 200, "Registration options created", options)
+}
+
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userId, ok := currentUserId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidToken)
+		return
+	}
+
+	sessionId, ok := h.sessionId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidWebAuthnSession)
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(c.Request.Body)
+	if err != nil {
+		response.Fail(c, errs.InvalidRequestBody)
+		return
+	}
+
+	err = h.webAuthnService.FinishRegistration(c.Request.Context(), userId, sessionId, parsed)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 201, "Passkey registered", nil)
+}
+
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	var loginBody dto.WebAuthnLoginBeginBody
+
+	if err := c.ShouldBindJSON(&loginBody); err != nil && !errors.Is(err, io.EOF) {
+		response.Fail(c, validation.Error(err))
+		return
+	}
+
+	options, sessionId, err := h.webAuthnService.BeginLogin(c.Request.Context(), &loginBody)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieWebAuthn, sessionId.String(), h.challengeDuration*60)
+
+	response.JSON(c, 200, "Login options created", options)
+}
+
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	sessionId, ok := h.sessionId(c)
+	if !ok {
+		response.Fail(c, errs.InvalidWebAuthnSession)
+		return
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(c.Request.Body)
+	if err != nil {
+		response.Fail(c, errs.InvalidRequestBody)
+		return
+	}
+
+	resp, err := h.webAuthnService.FinishLogin(c.Request.Context(), sessionId, parsed)
+	metrics.ObserveLogin(metrics.LoginMethodWebAuthn, err)
+	if err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	if err := cookie.SetTokens(c, h.cookie, resp.AccesToken, resp.RefreshToken); err != nil {
+		response.Fail(c, err)
+		return
+	}
+
+	response.JSON(c, 200, "Login success", resp)
+}
+
+// sessionId reads and clears the ceremony cookie set by the begin endpoints.
+func (h *WebAuthnHandler) sessionId(c *gin.Context) (uuid.UUID, bool) {
+	value, err := c.Cookie(constant.CookieWebAuthn)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	cookie.Set(c, h.cookie, constant.CookieWebAuthn, "", -1)
+
+	sessionId, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return sessionId, true
+}
+
+func currentUserId(c *gin.Context) (uuid.UUID, bool) {
+	value, ok := c.Get(constant.ContextKeyUser)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	claims, ok := value.(tokenprovider.UserClaims)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	userId, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return userId, true
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion stores the version of the routes it is mounted on in the request
+// context, so handlers can serve version-specific DTOs. Deprecated versions
+// answer with Deprecation (RFC 9745), Sunset (RFC 8594) and a successor-version
+// Link.
+func APIVersion(version apiversion.Version) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Request = ctx.Request.WithContext(apiversion.WithVersion(ctx.Request.Context(), version.Name))
+
+		if version.Deprecated() {
+			ctx.Header("Deprecation", fmt.Sprintf("@%d", version.Deprecation.Unix()))
+			if !version.Sunset.IsZero() {
+				ctx.Header("Sunset", version.Sunset.UTC().Format(http.TimeFormat))
+			}
+			if version.Successor != "" {
+				path := strings.TrimPrefix(ctx.Request.URL.Path, version.Prefix())
+				ctx.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, apiversion.Prefix(version.Successor), path))
+			}
+		}
+
+		ctx.Next()
+	}
+}
@@ -2,36 +2,128 @@ package middleware
 
 import (
 	"errors"
-	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/EputraP/kfc_be/internal/constant"
 	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/audit"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
 	"github.com/EputraP/kfc_be/internal/util/response"
 	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
 	"github.com/gin-gonic/gin"
 )
 
-func CreateAuth(tokenChecker tokenprovider.JWTTokenProvider) gin.HandlerFunc {
+type AuthConfig struct {
+	Cookie cookie.Config
+	// TokenCookie is the cookie read when no Authorization header is sent.
+	TokenCookie string
+	// Sessions rejects tokens of deactivated users or revoked sessions when set.
+	Sessions service.SessionService
+}
+
+// Reasons reported by kfc_auth_token_validation_failures_total.
+const (
+	tokenFailureMissing       = "missing_token"
+	tokenFailureInvalidToken  = "invalid_token"
+	tokenFailureInvalidIssuer = "invalid_issuer"
+	tokenFailureBearerFormat  = "invalid_bearer_format"
+	tokenFailureRevoked       = "session_revoked"
+	tokenFailureOther         = "other"
+)
+
+func CreateAuth(tokenChecker tokenprovider.JWTTokenProvider, config AuthConfig) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authHeader := ctx.Request.Header.Get("Authorization")
-		tokenStr, err := tokenChecker.ExtractToken(authHeader)
-		if errors.Is(err, errs.InvalidBearerFormat) {
-			response.Error(ctx, http.StatusUnauthorized, err.Error())
-			return
+
+		var tokenStr string
+		var err error
+
+		if authHeader == "" && config.Cookie.Enabled {
+			tokenStr, err = ctx.Cookie(config.TokenCookie)
+			if err != nil || tokenStr == "" {
+				metrics.TokenValidationFailures.WithLabelValues(tokenFailureMissing).Inc()
+				response.Fail(ctx, errs.InvalidToken)
+				return
+			}
+
+			if !cookie.IsSafeMethod(ctx.Request.Method) && !cookie.ValidCSRF(ctx) {
+				response.Fail(ctx, errs.InvalidCSRFToken)
+				return
+			}
+		} else {
+			tokenStr, err = tokenChecker.ExtractToken(authHeader)
+			if err != nil {
+				metrics.TokenValidationFailures.WithLabelValues(tokenFailureReason(err)).Inc()
+				response.Fail(ctx, err)
+				return
+			}
 		}
 
 		claims, err := tokenChecker.ValidateToken(tokenStr)
-		if errors.Is(err, errs.InvalidToken) || errors.Is(err, errs.InvalidIssuer) {
-			response.Error(ctx, http.StatusUnauthorized, err.Error())
+		if err != nil {
+			metrics.TokenValidationFailures.WithLabelValues(tokenFailureReason(err)).Inc()
+			response.Fail(ctx, err)
 			return
 		}
 
-		if err != nil {
-			response.UnknownError(ctx, err)
-			return
+		if config.Sessions != nil {
+			var issuedAt time.Time
+			if claims.IssuedAt != nil {
+				issuedAt = claims.IssuedAt.Time
+			}
+
+			revoked, err := config.Sessions.IsRevoked(ctx.Request.Context(), claims.UserID, issuedAt)
+			if err != nil {
+				response.Fail(ctx, err)
+				return
+			}
+			if revoked {
+				metrics.TokenValidationFailures.WithLabelValues(tokenFailureReason(errs.SessionRevoked)).Inc()
+				response.Fail(ctx, errs.SessionRevoked)
+				return
+			}
+		}
+
+		if claims.IsImpersonated() {
+			if !cookie.IsSafeMethod(ctx.Request.Method) && !claims.HasScope(constant.ScopeWrite) {
+				response.Fail(ctx, errs.InsufficientScope)
+				return
+			}
+
+			ctx.Set(constant.ContextKeyActor, *claims.Actor)
 		}
 
 		ctx.Set(constant.ContextKeyUser, claims.UserClaims)
+		ctx.Request = ctx.Request.WithContext(logger.WithValue(ctx.Request.Context(), constant.LogKeyUserID, claims.UserID))
 		ctx.Next()
+
+		if claims.IsImpersonated() {
+			audit.Log(ctx.Request.Context(), "impersonated request", map[string]string{
+				"actorId": claims.Actor.UserID,
+				"userId":  claims.UserID,
+				"method":  ctx.Request.Method,
+				"path":    ctx.Request.URL.Path,
+				"status":  strconv.Itoa(ctx.Writer.Status()),
+			})
+		}
+	}
+}
+
+func tokenFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errs.InvalidIssuer):
+		return tokenFailureInvalidIssuer
+	case errors.Is(err, errs.InvalidToken):
+		return tokenFailureInvalidToken
+	case errors.Is(err, errs.InvalidBearerFormat):
+		return tokenFailureBearerFormat
+	case errors.Is(err, errs.SessionRevoked):
+		return tokenFailureRevoked
+	default:
+		return tokenFailureOther
 	}
 }
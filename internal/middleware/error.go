@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+type ErrorHandlerConfig struct {
+	// Problem serves every error as application/problem+json. Otherwise only
+	// requests that accept it get problem details, the rest the JSON envelope.
+	Problem bool
+}
+
+// ErrorHandler writes the error recorded with response.Fail. It must come
+// after the access log and metrics middleware so that they see the status it
+// writes; only Recovery is registered after it.
+func ErrorHandler(config ErrorHandlerConfig) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+
+		err := ctx.Errors.Last().Err
+		appErr := errs.AsAppError(err)
+
+		if appErr.Status >= http.StatusInternalServerError {
+			logger.ErrorContext(ctx.Request.Context(), "ErrorHandler", "Request failed", map[string]string{
+				"route": ctx.FullPath(),
+				"code":  appErr.Code,
+				"error": err.Error(),
+			})
+		} else {
+			logger.DebugContext(ctx.Request.Context(), "ErrorHandler", "Request rejected", map[string]string{
+				"route": ctx.FullPath(),
+				"code":  appErr.Code,
+				"error": err.Error(),
+			})
+		}
+
+		response.WriteError(ctx, appErr, config.Problem || acceptsProblem(ctx.GetHeader("Accept")))
+	}
+}
+
+func acceptsProblem(accept string) bool {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err == nil && mediaType == response.ContentTypeProblem {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+	"github.com/gin-gonic/gin"
+)
+
+// Language picks the response language from Accept-Language and reports it in
+// Content-Language.
+func Language() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lang := i18n.Negotiate(ctx.GetHeader("Accept-Language"))
+
+		ctx.Request = ctx.Request.WithContext(i18n.WithLanguage(ctx.Request.Context(), lang))
+		ctx.Header("Content-Language", lang)
+		ctx.Writer.Header().Add("Vary", "Accept-Language")
+
+		ctx.Next()
+	}
+}
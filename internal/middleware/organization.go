@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateRequireOrganization must run after CreateAuth. It resolves the
+// active organization of the token against the current memberships, so a
+// removed member loses access before the token expires.
+func CreateRequireOrganization(organizationService service.OrganizationService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, _ := ctx.Get(constant.ContextKeyUser)
+
+		claims, ok := value.(tokenprovider.UserClaims)
+		if !ok {
+			response.Fail(ctx, errs.InvalidToken)
+			return
+		}
+
+		organizationId, err := uuid.Parse(claims.OrganizationID)
+		if err != nil {
+			response.Fail(ctx, errs.OrganizationRequired)
+			return
+		}
+
+		userId, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			response.Fail(ctx, errs.InvalidToken)
+			return
+		}
+
+		membership, err := organizationService.SearchMembership(ctx.Request.Context(), organizationId, userId)
+		if err != nil {
+			response.Fail(ctx, err)
+			return
+		}
+
+		ctx.Set(constant.ContextKeyMembership, membership)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic into a 500 recorded with response.Fail. It must be
+// registered after ErrorHandler, so the access log, metrics and the error
+// response of a panicking request carry its request id and status.
+func Recovery() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logger.ErrorContext(ctx.Request.Context(), "Recovery", "Recovered from panic", map[string]string{
+				"route": ctx.FullPath(),
+				"panic": fmt.Sprint(recovered),
+				"stack": string(debug.Stack()),
+			})
+
+			response.Fail(ctx, errs.Internal)
+		}()
+
+		ctx.Next()
+	}
+}
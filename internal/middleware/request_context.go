@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const headerRequestID = "X-Request-ID"
+
+// maxRequestIDLength caps ids accepted from clients, which end up in every
+// log line of the request.
+const maxRequestIDLength = 128
+
+// RequestContext accepts the client's X-Request-ID or generates one, stores
+// it in the request context so that every log written while serving the
+// request can be correlated, and echoes it in the response. Once the request
+// is served it writes one access log line.
+func RequestContext() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		requestId := ctx.GetHeader(headerRequestID)
+		if !validRequestID(requestId) {
+			requestId = uuid.NewString()
+		}
+
+		ctx.Header(headerRequestID, requestId)
+		ctx.Request = ctx.Request.WithContext(logger.WithValue(ctx.Request.Context(), constant.LogKeyRequestID, requestId))
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := ctx.Writer.Status()
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		// ctx.Request now carries the user id set by the auth middleware
+		logger.AttrsContext(ctx.Request.Context(), level, "access",
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latencyMs", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", max(ctx.Writer.Size(), 0)),
+			slog.String("clientIp", ctx.ClientIP()),
+		)
+	}
+}
+
+// validRequestID accepts printable ASCII ids without spaces, so a client
+// cannot forge log fields with it.
+func validRequestID(requestId string) bool {
+	if requestId == "" || len(requestId) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range requestId {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
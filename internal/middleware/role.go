@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/service"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CreateRequireRole must run after CreateAuth. Impersonation tokens are
+// always rejected so that an admin session cannot be re-entered through an
+// impersonated user.
+func CreateRequireRole(roleService service.RoleService, role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, _ := ctx.Get(constant.ContextKeyUser)
+
+		claims, ok := value.(tokenprovider.UserClaims)
+		if !ok || claims.IsImpersonated() {
+			response.Fail(ctx, errs.ForbiddenAccess)
+			return
+		}
+
+		userId, err := uuid.Parse(claims.UserID)
+		if err != nil {
+			response.Fail(ctx, errs.InvalidToken)
+			return
+		}
+
+		hasRole, err := roleService.HasRole(ctx.Request.Context(), userId, role)
+		if err != nil {
+			response.Fail(ctx, err)
+			return
+		}
+		if !hasRole {
+			response.Fail(ctx, errs.ForbiddenAccess)
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"strings"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/scim"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSCIMAuth accepts requests carrying the shared bearer token configured
+// for the provisioning client. Every request is rejected when token is empty.
+func CreateSCIMAuth(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		bearer, ok := strings.CutPrefix(ctx.Request.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || !securetoken.Equal(bearer, token) {
+			scim.ErrorResponse(ctx, 401, "", errs.InvalidToken.Error())
+			return
+		}
+
+		ctx.Next()
+	}
+}
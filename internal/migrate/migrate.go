@@ -0,0 +1,385 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"gorm.io/gorm"
+)
+
+// advisoryLockKey is shared by every instance so that only one of them
+// applies migrations at a time.
+const advisoryLockKey = 7263511
+
+var (
+	fileNamePattern    = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	createTablePattern = regexp.MustCompile(`(?i)CREATE TABLE (\w+)`)
+)
+
+var ErrNoDownMigration = errors.New("migration has no down file")
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New loads the migrations from files, usually migration.FS().
+func New(db *gorm.DB, files fs.FS) (*Migrator, error) {
+	migrations, err := load(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Up applies every pending migration in version order and returns how many
+// were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		if err := m.baseline(ctx, conn, versions); err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := versions[migration.Version]; ok {
+				continue
+			}
+
+			logger.InfoContext(ctx, "Migrator Up", "Applying migration", map[string]string{
+				"version": strconv.FormatInt(migration.Version, 10),
+				"name":    migration.Name,
+			})
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+
+				return tx.Exec(`INSERT INTO schema_migrations (version, "name", applied_at) VALUES (?,?,?);`,
+					migration.Version, migration.Name, time.Now()).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			applied++
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the last steps applied migrations and returns how many were
+// reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := versions[migration.Version]; !ok {
+				continue
+			}
+			if migration.Down == "" {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, ErrNoDownMigration)
+			}
+
+			logger.InfoContext(ctx, "Migrator Down", "Reverting migration", map[string]string{
+				"version": strconv.FormatInt(migration.Version, 10),
+				"name":    migration.Name,
+			})
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+
+				return tx.Exec(`DELETE FROM schema_migrations WHERE version = ?;`, migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+
+			reverted++
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// Status lists every known migration with the time it was applied, nil when
+// it is pending.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		versions, err := m.appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := Status{Version: migration.Version, Name: migration.Name}
+			if appliedAt, ok := versions[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Pending returns how many migrations have not been applied yet. It backs
+// the readiness probe, so it only reads: every migration is pending while
+// schema_migrations does not exist.
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	conn := m.db.WithContext(ctx)
+
+	exists, err := tableExists(conn, "schema_migrations")
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return len(m.migrations), nil
+	}
+
+	versions, err := m.appliedVersions(conn)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, migration := range m.migrations {
+		if _, ok := versions[migration.Version]; !ok {
+			pending++
+		}
+	}
+
+	return pending, nil
+}
+
+// withLock runs fn on a single connection holding the migration advisory
+// lock, which pg_advisory_lock ties to the session. The unlock runs on the
+// same connection without ctx, so that a cancelled ctx cannot leave the lock
+// held by a connection returned to the pool.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(`SELECT pg_advisory_lock(?);`, advisoryLockKey).Error; err != nil {
+			return err
+		}
+		defer conn.WithContext(context.Background()).Exec(`SELECT pg_advisory_unlock(?);`, advisoryLockKey)
+
+		if err := m.ensureTable(conn); err != nil {
+			return err
+		}
+
+		return fn(conn)
+	})
+}
+
+func (m *Migrator) ensureTable(conn *gorm.DB) error {
+	return conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version bigint NOT NULL,
+		"name" varchar NOT NULL,
+		applied_at timestamptz NOT NULL,
+		CONSTRAINT schema_migrations_pkey PRIMARY KEY (version)
+	);`).Error
+}
+
+// baseline records migrations as applied without running them when
+// schema_migrations is empty but the database was created by the former
+// migration/up.sql init script. Migrations are recorded in order as long as
+// every table they create already exists.
+func (m *Migrator) baseline(ctx context.Context, conn *gorm.DB, versions map[int64]time.Time) error {
+	if len(versions) > 0 {
+		return nil
+	}
+
+	for _, migration := range m.migrations {
+		tables := createTablePattern.FindAllStringSubmatch(migration.Up, -1)
+		if len(tables) == 0 {
+			return nil
+		}
+
+		for _, table := range tables {
+			exists, err := tableExists(conn, table[1])
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return nil
+			}
+		}
+
+		logger.InfoContext(ctx, "Migrator baseline", "Recording existing schema as applied", map[string]string{
+			"version": strconv.FormatInt(migration.Version, 10),
+			"name":    migration.Name,
+		})
+
+		appliedAt := time.Now()
+		err := conn.Exec(`INSERT INTO schema_migrations (version, "name", applied_at) VALUES (?,?,?);`,
+			migration.Version, migration.Name, appliedAt).Error
+		if err != nil {
+			return err
+		}
+
+		versions[migration.Version] = appliedAt
+	}
+
+	return nil
+}
+
+func tableExists(conn *gorm.DB, table string) (bool, error) {
+	var exists bool
+
+	err := conn.Raw(`SELECT EXISTS (SELECT 1 FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?);`, table).Scan(&exists).Error
+
+	return exists, err
+}
+
+func (m *Migrator) appliedVersions(conn *gorm.DB) (map[int64]time.Time, error) {
+	rows := []struct {
+		Version   int64
+		AppliedAt time.Time
+	}{}
+
+	if err := conn.Raw(`SELECT version, applied_at FROM schema_migrations;`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		versions[row.Version] = row.AppliedAt
+	}
+
+	return versions, nil
+}
+
+// Create writes an up/down pair for name in dir, numbered after the
+// highest existing version, and returns the paths.
+func Create(dir string, name string) (string, string, error) {
+	name = strings.ToLower(strings.Join(strings.Fields(name), "_"))
+	if !regexp.MustCompile(`^\w+$`).MatchString(name) {
+		return "", "", fmt.Errorf("invalid migration name %q", name)
+	}
+
+	migrations, err := load(os.DirFS(dir))
+	if err != nil {
+		return "", "", err
+	}
+
+	var version int64 = 1
+	if len(migrations) > 0 {
+		version = migrations[len(migrations)-1].Version + 1
+	}
+
+	prefix := filepath.Join(dir, fmt.Sprintf("%04d_%s", version, name))
+	upPath, downPath := prefix+".up.sql", prefix+".down.sql"
+
+	if err := os.WriteFile(upPath, []byte("-- "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downPath, []byte("-- revert "+name+"\n"), 0644); err != nil {
+		return "", "", err
+	}
+
+	return upPath, downPath, nil
+}
+
+func load(files fs.FS) ([]Migration, error) {
+	paths, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+
+	for _, path := range paths {
+		match := fileNamePattern.FindStringSubmatch(path)
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q", path)
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+
+		content, err := fs.ReadFile(files, path)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
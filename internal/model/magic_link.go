@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type MagicLink struct {
+	Id        uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId    uuid.UUID  `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	TokenHash string     `json:"-" gorm:"column:token_hash;type:varchar;not null"`
+	NonceHash string     `json:"-" gorm:"column:nonce_hash;type:varchar;not null"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	UsedAt    *time.Time `json:"used_at" gorm:"column:used_at"`
+}
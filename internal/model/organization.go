@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Organization struct {
+	Id        uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name      string     `json:"name" gorm:"column:name;type:varchar;not null"`
+	CreatedBy uuid.UUID  `json:"created_by" gorm:"column:created_by;type:uuid;not null"`
+	CreatedAt *time.Time `json:"created_at" gorm:"column:created_at"`
+	// Role is the membership role of the user the organization was listed for.
+	Role string `json:"role,omitempty" gorm:"->;column:role"`
+}
+
+type Membership struct {
+	Id             uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	OrganizationId uuid.UUID  `json:"organization_id" gorm:"column:organization_id;type:uuid;not null"`
+	UserId         uuid.UUID  `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Role           string     `json:"role" gorm:"column:role;type:varchar;not null"`
+	Username       string     `json:"username,omitempty" gorm:"->;column:username"`
+	CreatedAt      *time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+type OrganizationInvitation struct {
+	Id             uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	OrganizationId uuid.UUID  `json:"organization_id" gorm:"column:organization_id;type:uuid;not null"`
+	Email          string     `json:"email" gorm:"column:email;type:varchar;not null"`
+	Role           string     `json:"role" gorm:"column:role;type:varchar;not null"`
+	TokenHash      string     `json:"-" gorm:"column:token_hash;type:varchar;not null"`
+	InvitedBy      uuid.UUID  `json:"invited_by" gorm:"column:invited_by;type:uuid;not null"`
+	ExpiresAt      time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	AcceptedAt     *time.Time `json:"accepted_at" gorm:"column:accepted_at"`
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type OTP struct {
+	Id          uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	PhoneNumber string     `json:"phone_number" gorm:"column:phone_number;type:varchar;not null"`
+	CodeHash    string     `json:"-" gorm:"column:code_hash;type:varchar;not null"`
+	Attempts    int        `json:"attempts" gorm:"column:attempts;not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+	ConsumedAt  *time.Time `json:"consumed_at" gorm:"column:consumed_at"`
+}
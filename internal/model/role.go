@@ -0,0 +1,14 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role struct {
+	Id        uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Name      string     `json:"name" gorm:"column:name;type:varchar;not null"`
+	CreatedAt *time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SessionState holds the columns of users that decide whether previously
+// issued tokens are still accepted.
+type SessionState struct {
+	Id                uuid.UUID  `json:"id" gorm:"column:id"`
+	DeletedAt         *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
+	SessionsRevokedAt *time.Time `json:"sessions_revoked_at" gorm:"column:sessions_revoked_at"`
+}
@@ -1,9 +1,17 @@
 package model
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type User struct {
-	Id       uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
-	Username string    `json:"username" gorm:"type:varchar;not null"`
-	Password string    `json:"password" gorm:"type:varchar;not null"`
+	Id        uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Username  string     `json:"username" gorm:"type:varchar;not null"`
+	Password  string     `json:"password" gorm:"type:varchar;not null"`
+	DeletedAt *time.Time `json:"-" gorm:"column:deleted_at"`
+	// OrganizationId is the active organization written into issued tokens.
+	OrganizationId  uuid.UUID   `json:"-" gorm:"-"`
+	OrganizationIds []uuid.UUID `json:"-" gorm:"-"`
 }
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserAccount is a user joined with its details, as managed through SCIM.
+type UserAccount struct {
+	Id          uuid.UUID  `json:"id" gorm:"column:id"`
+	Username    string     `json:"username" gorm:"column:username"`
+	ExternalId  string     `json:"external_id" gorm:"column:external_id"`
+	Email       string     `json:"email" gorm:"column:email"`
+	PhoneNumber string     `json:"phone_number" gorm:"column:phone_number"`
+	Address     string     `json:"address" gorm:"column:address"`
+	CreatedAt   *time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   *time.Time `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at" gorm:"column:deleted_at"`
+}
+
+// UserFilter restricts a user listing to rows where Column compares to Value
+// with Operator. Column must come from a whitelist, never from user input.
+type UserFilter struct {
+	Column   string
+	Operator string
+	Value    string
+}
@@ -0,0 +1,12 @@
+package model
+
+import "github.com/google/uuid"
+
+type UserDetail struct {
+	Id          uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId      uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Email       string    `json:"email" gorm:"type:varchar;not null"`
+	Address     string    `json:"address" gorm:"type:varchar;not null"`
+	PhoneNumber string    `json:"phone_number" gorm:"column:phone_number;type:varchar;not null"`
+	Age         int       `json:"age" gorm:"not null"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type UserIdentity struct {
+	Id       uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId   uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Provider string    `json:"provider" gorm:"column:provider;type:varchar;not null"`
+	Subject  string    `json:"subject" gorm:"column:subject;type:varchar;not null"`
+	Email    string    `json:"email" gorm:"column:email;type:varchar"`
+	// UserDeactivated is set when the linked user has been deactivated.
+	UserDeactivated bool `json:"-" gorm:"->;column:user_deactivated"`
+}
+
+type OAuthState struct {
+	Id           uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	Provider     string    `json:"provider" gorm:"column:provider;type:varchar;not null"`
+	StateHash    string    `json:"-" gorm:"column:state_hash;type:varchar;not null"`
+	Nonce        string    `json:"-" gorm:"column:nonce;type:varchar;not null"`
+	CodeVerifier string    `json:"-" gorm:"column:code_verifier;type:varchar;not null"`
+	ExpiresAt    time.Time `json:"expires_at" gorm:"column:expires_at;not null"`
+}
@@ -0,0 +1,12 @@
+package model
+
+import "github.com/google/uuid"
+
+type UserRole struct {
+	Id     uuid.UUID `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	Role   string    `json:"role" gorm:"column:role;type:varchar;not null"`
+	// Source is the system that granted the role, e.g. "ldap", so that
+	// synchronising one source leaves roles granted elsewhere intact.
+	Source string `json:"source" gorm:"column:source;type:varchar;not null"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebAuthnCredential struct {
+	Id              uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId          uuid.UUID  `json:"user_id" gorm:"column:user_id;type:uuid;not null"`
+	CredentialId    []byte     `json:"credential_id" gorm:"column:credential_id;type:bytea;not null"`
+	PublicKey       []byte     `json:"-" gorm:"column:public_key;type:bytea;not null"`
+	AttestationType string     `json:"attestation_type" gorm:"column:attestation_type;type:varchar"`
+	AAGUID          []byte     `json:"aaguid" gorm:"column:aaguid;type:bytea"`
+	SignCount       int64      `json:"sign_count" gorm:"column:sign_count;not null"`
+	Transports      string     `json:"transports" gorm:"column:transports;type:varchar"`
+	Attachment      string     `json:"attachment" gorm:"column:attachment;type:varchar"`
+	UserPresent     bool       `json:"user_present" gorm:"column:user_present"`
+	UserVerified    bool       `json:"user_verified" gorm:"column:user_verified"`
+	BackupEligible  bool       `json:"backup_eligible" gorm:"column:backup_eligible"`
+	BackupState     bool       `json:"backup_state" gorm:"column:backup_state"`
+	CloneWarning    bool       `json:"clone_warning" gorm:"column:clone_warning"`
+	LastUsedAt      *time.Time `json:"last_used_at" gorm:"column:last_used_at"`
+}
+
+type WebAuthnChallenge struct {
+	Id          uuid.UUID  `json:"id" gorm:"column:id;type:uuid;primaryKey;default:uuid_generate_v4()"`
+	UserId      *uuid.UUID `json:"user_id" gorm:"column:user_id;type:uuid"`
+	Ceremony    string     `json:"ceremony" gorm:"column:ceremony;type:varchar;not null"`
+	SessionData []byte     `json:"-" gorm:"column:session_data;type:jsonb;not null"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"column:expires_at;not null"`
+}
@@ -1,18 +1,26 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
 	"github.com/EputraP/kfc_be/internal/model"
 	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/tracing"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type AuthRepository interface {
 	WithTx(tx *gorm.DB) AuthRepository
-	CreateUser(input *dto.RegisterBody) (*model.User, error)
-	SearchUserByUsername(input *dto.RegisterBody) (*model.User, error)
+	CreateUser(ctx context.Context, input *dto.RegisterBody) (*model.User, error)
+	SearchUserByUsername(ctx context.Context, input *dto.RegisterBody) (*model.User, error)
+	SearchUserByEmail(ctx context.Context, input *dto.MagicLinkBody) (*model.User, error)
+	SearchUserById(ctx context.Context, userId uuid.UUID) (*model.User, error)
+	SearchUserByPhoneNumber(ctx context.Context, phoneNumber string) (*model.User, error)
+	CreateUserDetail(ctx context.Context, input *model.UserDetail) (*model.UserDetail, error)
 }
 
 type authRepository struct {
@@ -30,9 +38,11 @@ func (r authRepository) WithTx(tx *gorm.DB) AuthRepository {
 	}
 }
 
-func (r *authRepository) CreateUser(input *dto.RegisterBody) (*model.User, error) {
+func (r *authRepository) CreateUser(ctx context.Context, input *dto.RegisterBody) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "authRepository CreateUser")
+	defer span.End()
 
-	logger.Info("userRepository CreateUser", "Executing CreateUser SQL query", map[string]string{
+	logger.InfoContext(ctx, "userRepository CreateUser", "Executing CreateUser SQL query", map[string]string{
 		"username": input.Username,
 	})
 
@@ -42,48 +52,192 @@ func (r *authRepository) CreateUser(input *dto.RegisterBody) (*model.User, error
 				VALUES (?,?,?) 
 				RETURNING id, username;`
 
-	res := r.db.Raw(sqlScript, input.Username, input.Password, time.Now()).Scan(resultModel)
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.Username, input.Password, time.Now()).Scan(resultModel)
 
 	if res.Error != nil {
-		logger.Error("userRepository CreateUser", "Failed to create user", map[string]string{
+		logger.ErrorContext(ctx, "userRepository CreateUser", "Failed to create user", map[string]string{
 			"username": input.Username,
 		})
+		if isUniqueViolation(res.Error) {
+			return nil, errs.UsernameAlreadyUsed
+		}
 		return nil, res.Error
 	}
 
-	logger.Info("userRepository CreateUser", "Successfully created user in CreateUser", map[string]string{
+	logger.InfoContext(ctx, "userRepository CreateUser", "Successfully created user in CreateUser", map[string]string{
 		"username": input.Username,
 	})
 
 	return resultModel, nil
 }
 
-func (r *authRepository) SearchUserByUsername(input *dto.RegisterBody) (*model.User, error) {
+func (r *authRepository) SearchUserByUsername(ctx context.Context, input *dto.RegisterBody) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "authRepository SearchUserByUsername")
+	defer span.End()
 
-	logger.Info("userRepository SearchUserByUsername", "Executing SearchUserByUsername SQL query", map[string]string{
+	logger.InfoContext(ctx, "userRepository SearchUserByUsername", "Executing SearchUserByUsername SQL query", map[string]string{
 		"username": input.Username,
 	})
 
 	resultModel := &model.User{}
 
-	sqlScript := `SELECT id, username, "password"
+	sqlScript := `SELECT id, username, "password", deleted_at
 				  FROM
 					users u 
 				  WHERE
 					username = ?;`
 
-	res := r.db.Raw(sqlScript, input.Username).Scan(resultModel)
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.Username).Scan(resultModel)
 
 	if res.Error != nil {
-		logger.Error("userRepository SearchUserByUsername", "Failed to search user", map[string]string{
+		logger.ErrorContext(ctx, "userRepository SearchUserByUsername", "Failed to search user", map[string]string{
 			"username": input.Username,
 		})
 		return nil, res.Error
 	}
 
-	logger.Info("userRepository SearchUserByUsername", "Successfully ran SearchUserByUsername", map[string]string{
+	logger.InfoContext(ctx, "userRepository SearchUserByUsername", "Successfully ran SearchUserByUsername", map[string]string{
 		"username": input.Username,
 	})
 
 	return resultModel, nil
 }
+
+// SearchUserByEmail also returns deactivated users, with DeletedAt set, so
+// that callers do not register the address again. Active users come first.
+func (r *authRepository) SearchUserByEmail(ctx context.Context, input *dto.MagicLinkBody) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "authRepository SearchUserByEmail")
+	defer span.End()
+
+	logger.InfoContext(ctx, "userRepository SearchUserByEmail", "Executing SearchUserByEmail SQL query", map[string]string{
+		"email": input.Email,
+	})
+
+	resultModel := &model.User{}
+
+	sqlScript := `SELECT u.id, u.username, u.deleted_at
+				  FROM
+					users u
+					JOIN user_details ud ON ud.user_id = u.id
+				  WHERE
+					lower(ud.email) = lower(?)
+					AND ud.deleted_at IS NULL
+				  ORDER BY u.deleted_at IS NOT NULL
+				  LIMIT 1;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.Email).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository SearchUserByEmail", "Failed to search user", map[string]string{
+			"email": input.Email,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository SearchUserByEmail", "Successfully ran SearchUserByEmail", map[string]string{
+		"email": input.Email,
+	})
+
+	return resultModel, nil
+}
+
+func (r *authRepository) SearchUserById(ctx context.Context, userId uuid.UUID) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "authRepository SearchUserById")
+	defer span.End()
+
+	logger.InfoContext(ctx, "userRepository SearchUserById", "Executing SearchUserById SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	resultModel := &model.User{}
+
+	sqlScript := `SELECT id, username
+				  FROM
+					users u
+				  WHERE
+					id = ?
+					AND deleted_at IS NULL;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository SearchUserById", "Failed to search user", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository SearchUserById", "Successfully ran SearchUserById", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return resultModel, nil
+}
+
+// SearchUserByPhoneNumber also returns deactivated users, with DeletedAt set,
+// so that callers do not register the number again. Active users come first.
+func (r *authRepository) SearchUserByPhoneNumber(ctx context.Context, phoneNumber string) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "authRepository SearchUserByPhoneNumber")
+	defer span.End()
+
+	logger.InfoContext(ctx, "userRepository SearchUserByPhoneNumber", "Executing SearchUserByPhoneNumber SQL query", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	resultModel := &model.User{}
+
+	sqlScript := `SELECT u.id, u.username, u.deleted_at
+				  FROM
+					users u
+					JOIN user_details ud ON ud.user_id = u.id
+				  WHERE
+					ud.phone_number = ?
+					AND ud.deleted_at IS NULL
+				  ORDER BY u.deleted_at IS NOT NULL
+				  LIMIT 1;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, phoneNumber).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository SearchUserByPhoneNumber", "Failed to search user", map[string]string{
+			"phoneNumber": phoneNumber,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository SearchUserByPhoneNumber", "Successfully ran SearchUserByPhoneNumber", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	return resultModel, nil
+}
+
+func (r *authRepository) CreateUserDetail(ctx context.Context, input *model.UserDetail) (*model.UserDetail, error) {
+	ctx, span := tracing.Start(ctx, "authRepository CreateUserDetail")
+	defer span.End()
+
+	logger.InfoContext(ctx, "userRepository CreateUserDetail", "Executing CreateUserDetail SQL query", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	resultModel := &model.UserDetail{}
+
+	sqlScript := `INSERT INTO user_details (user_id, email, "address", phone_number, age, created_at)
+				VALUES (?,?,?,?,?,?)
+				RETURNING id, user_id, email, "address", phone_number, age;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.UserId, input.Email, input.Address, input.PhoneNumber, input.Age, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository CreateUserDetail", "Failed to create user detail", map[string]string{
+			"userId": input.UserId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository CreateUserDetail", "Successfully created user detail", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	return resultModel, nil
+}
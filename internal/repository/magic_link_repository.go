@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepository interface {
+	WithTx(tx *gorm.DB) MagicLinkRepository
+	CreateMagicLink(ctx context.Context, input *model.MagicLink) (*model.MagicLink, error)
+	ConsumeMagicLink(ctx context.Context, tokenHash string, nonceHash string) (*model.MagicLink, error)
+	InvalidateMagicLinks(ctx context.Context, userId uuid.UUID) error
+}
+
+type magicLinkRepository struct {
+	db *gorm.DB
+}
+
+func NewMagicLinkRepository(db *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{
+		db: db,
+	}
+}
+
+func (r magicLinkRepository) WithTx(tx *gorm.DB) MagicLinkRepository {
+	return &magicLinkRepository{
+		db: tx,
+	}
+}
+
+func (r *magicLinkRepository) CreateMagicLink(ctx context.Context, input *model.MagicLink) (*model.MagicLink, error) {
+
+	logger.InfoContext(ctx, "magicLinkRepository CreateMagicLink", "Executing CreateMagicLink SQL query", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	resultModel := &model.MagicLink{}
+
+	sqlScript := `INSERT INTO magic_links (user_id, token_hash, nonce_hash, expires_at, created_at)
+				VALUES (?,?,?,?,?)
+				RETURNING id, user_id, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.UserId, input.TokenHash, input.NonceHash, input.ExpiresAt, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "magicLinkRepository CreateMagicLink", "Failed to create magic link", map[string]string{
+			"userId": input.UserId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "magicLinkRepository CreateMagicLink", "Successfully created magic link", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	return resultModel, nil
+}
+
+// ConsumeMagicLink marks an unused, unexpired link issued to the same device
+// as used and returns it. An empty model is returned when no link matches.
+func (r *magicLinkRepository) ConsumeMagicLink(ctx context.Context, tokenHash string, nonceHash string) (*model.MagicLink, error) {
+
+	logger.InfoContext(ctx, "magicLinkRepository ConsumeMagicLink", "Executing ConsumeMagicLink SQL query", nil)
+
+	resultModel := &model.MagicLink{}
+
+	sqlScript := `UPDATE magic_links
+				  SET used_at = ?
+				  WHERE
+					token_hash = ?
+					AND nonce_hash = ?
+					AND used_at IS NULL
+					AND expires_at > ?
+				  RETURNING id, user_id, expires_at, used_at;`
+
+	now := time.Now()
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, now, tokenHash, nonceHash, now).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "magicLinkRepository ConsumeMagicLink", "Failed to consume magic link", map[string]string{
+			"error": res.Error.Error(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "magicLinkRepository ConsumeMagicLink", "Successfully ran ConsumeMagicLink", map[string]string{
+		"magicLinkId": resultModel.Id.String(),
+	})
+
+	return resultModel, nil
+}
+
+// InvalidateMagicLinks marks every unused link of the user as used so that
+// only the most recent one can be redeemed.
+func (r *magicLinkRepository) InvalidateMagicLinks(ctx context.Context, userId uuid.UUID) error {
+
+	logger.InfoContext(ctx, "magicLinkRepository InvalidateMagicLinks", "Executing InvalidateMagicLinks SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	sqlScript := `UPDATE magic_links
+				  SET used_at = ?
+				  WHERE
+					user_id = ?
+					AND used_at IS NULL;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, time.Now(), userId)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "magicLinkRepository InvalidateMagicLinks", "Failed to invalidate magic links", map[string]string{
+			"userId": userId.String(),
+			"error":  res.Error.Error(),
+		})
+		return res.Error
+	}
+
+	logger.InfoContext(ctx, "magicLinkRepository InvalidateMagicLinks", "Successfully invalidated magic links", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MembershipRepository is tenant scoped: every query is filtered by the
+// organization set with ForOrganization and fails with
+// errs.OrganizationRequired when none is set.
+type MembershipRepository interface {
+	WithTx(tx *gorm.DB) MembershipRepository
+	ForOrganization(organizationId uuid.UUID) MembershipRepository
+	SearchMembership(ctx context.Context, userId uuid.UUID) (*model.Membership, error)
+	ListMemberships(ctx context.Context) ([]model.Membership, error)
+	CreateMembership(ctx context.Context, userId uuid.UUID, role string) (*model.Membership, error)
+	CreateInvitation(ctx context.Context, input *model.OrganizationInvitation) (*model.OrganizationInvitation, error)
+}
+
+type membershipRepository struct {
+	db             *gorm.DB
+	organizationId uuid.UUID
+}
+
+func NewMembershipRepository(db *gorm.DB) MembershipRepository {
+	return &membershipRepository{
+		db: db,
+	}
+}
+
+func (r membershipRepository) WithTx(tx *gorm.DB) MembershipRepository {
+	return &membershipRepository{
+		db:             tx,
+		organizationId: r.organizationId,
+	}
+}
+
+func (r membershipRepository) ForOrganization(organizationId uuid.UUID) MembershipRepository {
+	return &membershipRepository{
+		db:             r.db,
+		organizationId: organizationId,
+	}
+}
+
+func (r *membershipRepository) SearchMembership(ctx context.Context, userId uuid.UUID) (*model.Membership, error) {
+	if r.organizationId == uuid.Nil {
+		return nil, errs.OrganizationRequired
+	}
+
+	logger.InfoContext(ctx, "membershipRepository SearchMembership", "Executing SearchMembership SQL query", map[string]string{
+		"organizationId": r.organizationId.String(),
+		"userId":         userId.String(),
+	})
+
+	resultModel := &model.Membership{}
+
+	sqlScript := `SELECT m.id, m.organization_id, m.user_id, m."role", u.username, m.created_at
+				  FROM
+					memberships m
+					JOIN users u ON u.id = m.user_id
+					JOIN organizations o ON o.id = m.organization_id
+				  WHERE
+					m.organization_id = ?
+					AND m.user_id = ?
+					AND u.deleted_at IS NULL
+					AND o.deleted_at IS NULL;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, r.organizationId, userId).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "membershipRepository SearchMembership", "Failed to search membership", map[string]string{
+			"organizationId": r.organizationId.String(),
+			"userId":         userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+func (r *membershipRepository) ListMemberships(ctx context.Context) ([]model.Membership, error) {
+	if r.organizationId == uuid.Nil {
+		return nil, errs.OrganizationRequired
+	}
+
+	logger.InfoContext(ctx, "membershipRepository ListMemberships", "Executing ListMemberships SQL query", map[string]string{
+		"organizationId": r.organizationId.String(),
+	})
+
+	resultModel := []model.Membership{}
+
+	sqlScript := `SELECT m.id, m.organization_id, m.user_id, m."role", u.username, m.created_at
+				  FROM
+					memberships m
+					JOIN users u ON u.id = m.user_id
+				  WHERE
+					m.organization_id = ?
+					AND u.deleted_at IS NULL
+				  ORDER BY m.created_at, m.id;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, r.organizationId).Scan(&resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "membershipRepository ListMemberships", "Failed to list memberships", map[string]string{
+			"organizationId": r.organizationId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// CreateMembership adds the user to the organization, keeping the existing
+// role when the user is already a member.
+func (r *membershipRepository) CreateMembership(ctx context.Context, userId uuid.UUID, role string) (*model.Membership, error) {
+	if r.organizationId == uuid.Nil {
+		return nil, errs.OrganizationRequired
+	}
+
+	logger.InfoContext(ctx, "membershipRepository CreateMembership", "Executing CreateMembership SQL query", map[string]string{
+		"organizationId": r.organizationId.String(),
+		"userId":         userId.String(),
+	})
+
+	now := time.Now()
+
+	sqlScript := `INSERT INTO memberships (organization_id, user_id, "role", created_at, updated_at)
+				VALUES (?,?,?,?,?)
+				ON CONFLICT (organization_id, user_id) DO NOTHING;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, r.organizationId, userId, role, now, now)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "membershipRepository CreateMembership", "Failed to create membership", map[string]string{
+			"organizationId": r.organizationId.String(),
+			"userId":         userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return r.SearchMembership(ctx, userId)
+}
+
+func (r *membershipRepository) CreateInvitation(ctx context.Context, input *model.OrganizationInvitation) (*model.OrganizationInvitation, error) {
+	if r.organizationId == uuid.Nil {
+		return nil, errs.OrganizationRequired
+	}
+
+	logger.InfoContext(ctx, "membershipRepository CreateInvitation", "Executing CreateInvitation SQL query", map[string]string{
+		"organizationId": r.organizationId.String(),
+		"email":          input.Email,
+	})
+
+	resultModel := &model.OrganizationInvitation{}
+
+	sqlScript := `INSERT INTO organization_invitations (organization_id, email, "role", token_hash, invited_by, expires_at, created_at)
+				VALUES (?,?,?,?,?,?,?)
+				RETURNING id, organization_id, email, "role", invited_by, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, r.organizationId, input.Email, input.Role, input.TokenHash, input.InvitedBy, input.ExpiresAt, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "membershipRepository CreateInvitation", "Failed to create invitation", map[string]string{
+			"organizationId": r.organizationId.String(),
+			"email":          input.Email,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
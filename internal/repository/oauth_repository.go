@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"gorm.io/gorm"
+)
+
+type OAuthRepository interface {
+	WithTx(tx *gorm.DB) OAuthRepository
+	CreateState(ctx context.Context, input *model.OAuthState) (*model.OAuthState, error)
+	ConsumeState(ctx context.Context, provider string, stateHash string) (*model.OAuthState, error)
+	SearchIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error)
+	CreateIdentity(ctx context.Context, input *model.UserIdentity) (*model.UserIdentity, error)
+}
+
+type oauthRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepository(db *gorm.DB) OAuthRepository {
+	return &oauthRepository{
+		db: db,
+	}
+}
+
+func (r oauthRepository) WithTx(tx *gorm.DB) OAuthRepository {
+	return &oauthRepository{
+		db: tx,
+	}
+}
+
+func (r *oauthRepository) CreateState(ctx context.Context, input *model.OAuthState) (*model.OAuthState, error) {
+
+	logger.InfoContext(ctx, "oauthRepository CreateState", "Executing CreateState SQL query", map[string]string{
+		"provider": input.Provider,
+	})
+
+	resultModel := &model.OAuthState{}
+
+	sqlScript := `INSERT INTO oauth_states (provider, state_hash, nonce, code_verifier, expires_at, created_at)
+				VALUES (?,?,?,?,?,?)
+				RETURNING id, provider, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.Provider, input.StateHash, input.Nonce, input.CodeVerifier, input.ExpiresAt, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "oauthRepository CreateState", "Failed to create state", map[string]string{
+			"provider": input.Provider,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// ConsumeState deletes an unexpired state of the provider and returns it, or
+// an empty model when none matches.
+func (r *oauthRepository) ConsumeState(ctx context.Context, provider string, stateHash string) (*model.OAuthState, error) {
+
+	logger.InfoContext(ctx, "oauthRepository ConsumeState", "Executing ConsumeState SQL query", map[string]string{
+		"provider": provider,
+	})
+
+	resultModel := &model.OAuthState{}
+
+	sqlScript := `DELETE FROM oauth_states
+				  WHERE
+					provider = ?
+					AND state_hash = ?
+					AND expires_at > ?
+				  RETURNING id, provider, nonce, code_verifier, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, provider, stateHash, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "oauthRepository ConsumeState", "Failed to consume state", map[string]string{
+			"provider": provider,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+func (r *oauthRepository) SearchIdentity(ctx context.Context, provider string, subject string) (*model.UserIdentity, error) {
+
+	logger.InfoContext(ctx, "oauthRepository SearchIdentity", "Executing SearchIdentity SQL query", map[string]string{
+		"provider": provider,
+		"subject":  subject,
+	})
+
+	resultModel := &model.UserIdentity{}
+
+	sqlScript := `SELECT ui.id, ui.user_id, ui.provider, ui.subject, ui.email,
+					u.deleted_at IS NOT NULL AS user_deactivated
+				  FROM
+					user_identities ui
+					JOIN users u ON u.id = ui.user_id
+				  WHERE
+					ui.provider = ?
+					AND ui.subject = ?;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, provider, subject).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "oauthRepository SearchIdentity", "Failed to search identity", map[string]string{
+			"provider": provider,
+			"subject":  subject,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "oauthRepository SearchIdentity", "Successfully ran SearchIdentity", map[string]string{
+		"provider": provider,
+		"subject":  subject,
+	})
+
+	return resultModel, nil
+}
+
+func (r *oauthRepository) CreateIdentity(ctx context.Context, input *model.UserIdentity) (*model.UserIdentity, error) {
+
+	logger.InfoContext(ctx, "oauthRepository CreateIdentity", "Executing CreateIdentity SQL query", map[string]string{
+		"provider": input.Provider,
+		"userId":   input.UserId.String(),
+	})
+
+	resultModel := &model.UserIdentity{}
+
+	sqlScript := `INSERT INTO user_identities (user_id, provider, subject, email, created_at)
+				VALUES (?,?,?,?,?)
+				RETURNING id, user_id, provider, subject, email;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.UserId, input.Provider, input.Subject, input.Email, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "oauthRepository CreateIdentity", "Failed to create identity", map[string]string{
+			"provider": input.Provider,
+			"userId":   input.UserId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "oauthRepository CreateIdentity", "Successfully created identity", map[string]string{
+		"provider": input.Provider,
+		"userId":   input.UserId.String(),
+	})
+
+	return resultModel, nil
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository holds the queries that are not bound to a single
+// tenant. Queries inside an organization go through MembershipRepository.
+type OrganizationRepository interface {
+	WithTx(tx *gorm.DB) OrganizationRepository
+	CreateOrganization(ctx context.Context, name string, createdBy uuid.UUID) (*model.Organization, error)
+	SearchOrganizationsByUserId(ctx context.Context, userId uuid.UUID) ([]model.Organization, error)
+	ConsumeInvitation(ctx context.Context, tokenHash string) (*model.OrganizationInvitation, error)
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+func (r organizationRepository) WithTx(tx *gorm.DB) OrganizationRepository {
+	return &organizationRepository{
+		db: tx,
+	}
+}
+
+func (r *organizationRepository) CreateOrganization(ctx context.Context, name string, createdBy uuid.UUID) (*model.Organization, error) {
+
+	logger.InfoContext(ctx, "organizationRepository CreateOrganization", "Executing CreateOrganization SQL query", map[string]string{
+		"name": name,
+	})
+
+	now := time.Now()
+	resultModel := &model.Organization{}
+
+	sqlScript := `INSERT INTO organizations ("name", created_by, created_at, updated_at)
+				VALUES (?,?,?,?)
+				RETURNING id, "name", created_by, created_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, name, createdBy, now, now).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "organizationRepository CreateOrganization", "Failed to create organization", map[string]string{
+			"name": name,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "organizationRepository CreateOrganization", "Successfully created organization", map[string]string{
+		"organizationId": resultModel.Id.String(),
+	})
+
+	return resultModel, nil
+}
+
+// SearchOrganizationsByUserId lists the organizations the user is a member
+// of together with the user's role in each.
+func (r *organizationRepository) SearchOrganizationsByUserId(ctx context.Context, userId uuid.UUID) ([]model.Organization, error) {
+
+	logger.InfoContext(ctx, "organizationRepository SearchOrganizationsByUserId", "Executing SearchOrganizationsByUserId SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	resultModel := []model.Organization{}
+
+	sqlScript := `SELECT o.id, o."name", o.created_by, o.created_at, m."role"
+				  FROM
+					organizations o
+					JOIN memberships m ON m.organization_id = o.id
+				  WHERE
+					m.user_id = ?
+					AND o.deleted_at IS NULL
+				  ORDER BY o.created_at, o.id;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(&resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "organizationRepository SearchOrganizationsByUserId", "Failed to search organizations", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// ConsumeInvitation marks an unaccepted, unexpired invitation as accepted and
+// returns it. An empty model is returned when no invitation matches.
+func (r *organizationRepository) ConsumeInvitation(ctx context.Context, tokenHash string) (*model.OrganizationInvitation, error) {
+
+	logger.InfoContext(ctx, "organizationRepository ConsumeInvitation", "Executing ConsumeInvitation SQL query", nil)
+
+	resultModel := &model.OrganizationInvitation{}
+
+	sqlScript := `UPDATE organization_invitations
+				  SET accepted_at = ?
+				  WHERE
+					token_hash = ?
+					AND accepted_at IS NULL
+					AND expires_at > ?
+				  RETURNING id, organization_id, email, "role", invited_by, expires_at, accepted_at;`
+
+	now := time.Now()
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, now, tokenHash, now).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "organizationRepository ConsumeInvitation", "Failed to consume invitation", nil)
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
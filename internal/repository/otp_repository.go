@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OTPRepository interface {
+	WithTx(tx *gorm.DB) OTPRepository
+	CreateOTP(ctx context.Context, input *model.OTP) (*model.OTP, error)
+	SearchActiveOTP(ctx context.Context, phoneNumber string) (*model.OTP, error)
+	ClaimOTPAttempt(ctx context.Context, otpId uuid.UUID, maxAttempts int) (bool, error)
+	CountOTPsSince(ctx context.Context, phoneNumber string, since time.Time) (int64, error)
+	LockPhoneNumber(ctx context.Context, phoneNumber string) error
+	ConsumeOTP(ctx context.Context, otpId uuid.UUID) (bool, error)
+	ConsumeOTPs(ctx context.Context, phoneNumber string) error
+}
+
+type otpRepository struct {
+	db *gorm.DB
+}
+
+func NewOTPRepository(db *gorm.DB) OTPRepository {
+	return &otpRepository{
+		db: db,
+	}
+}
+
+func (r otpRepository) WithTx(tx *gorm.DB) OTPRepository {
+	return &otpRepository{
+		db: tx,
+	}
+}
+
+func (r *otpRepository) CreateOTP(ctx context.Context, input *model.OTP) (*model.OTP, error) {
+
+	logger.InfoContext(ctx, "otpRepository CreateOTP", "Executing CreateOTP SQL query", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	resultModel := &model.OTP{}
+
+	sqlScript := `INSERT INTO otp_codes (phone_number, code_hash, attempts, expires_at, created_at)
+				VALUES (?,?,0,?,?)
+				RETURNING id, phone_number, attempts, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.PhoneNumber, input.CodeHash, input.ExpiresAt, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository CreateOTP", "Failed to create OTP", map[string]string{
+			"phoneNumber": input.PhoneNumber,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "otpRepository CreateOTP", "Successfully created OTP", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	return resultModel, nil
+}
+
+// SearchActiveOTP returns the latest unconsumed, unexpired code for the phone
+// number, or an empty model when there is none.
+func (r *otpRepository) SearchActiveOTP(ctx context.Context, phoneNumber string) (*model.OTP, error) {
+
+	logger.InfoContext(ctx, "otpRepository SearchActiveOTP", "Executing SearchActiveOTP SQL query", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	resultModel := &model.OTP{}
+
+	sqlScript := `SELECT id, phone_number, code_hash, attempts, expires_at
+				  FROM
+					otp_codes
+				  WHERE
+					phone_number = ?
+					AND consumed_at IS NULL
+					AND expires_at > ?
+				  ORDER BY created_at DESC
+				  LIMIT 1;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, phoneNumber, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository SearchActiveOTP", "Failed to search OTP", map[string]string{
+			"phoneNumber": phoneNumber,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "otpRepository SearchActiveOTP", "Successfully ran SearchActiveOTP", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	return resultModel, nil
+}
+
+// ClaimOTPAttempt counts an attempt against the code and reports whether it
+// was still under maxAttempts. The check and the increment are one statement,
+// so parallel guesses cannot exceed the limit.
+func (r *otpRepository) ClaimOTPAttempt(ctx context.Context, otpId uuid.UUID, maxAttempts int) (bool, error) {
+
+	logger.InfoContext(ctx, "otpRepository ClaimOTPAttempt", "Executing ClaimOTPAttempt SQL query", map[string]string{
+		"otpId": otpId.String(),
+	})
+
+	var attempts []int
+
+	sqlScript := `UPDATE otp_codes
+				  SET attempts = attempts + 1
+				  WHERE id = ? AND attempts < ?
+				  RETURNING attempts;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, otpId, maxAttempts).Scan(&attempts)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository ClaimOTPAttempt", "Failed to claim OTP attempt", map[string]string{
+			"otpId": otpId.String(),
+		})
+		return false, res.Error
+	}
+
+	return len(attempts) > 0, nil
+}
+
+// CountOTPsSince counts the codes issued to the phone number since a time,
+// consumed or not.
+func (r *otpRepository) CountOTPsSince(ctx context.Context, phoneNumber string, since time.Time) (int64, error) {
+
+	logger.InfoContext(ctx, "otpRepository CountOTPsSince", "Executing CountOTPsSince SQL query", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	var count int64
+
+	sqlScript := `SELECT COUNT(*)
+				  FROM
+					otp_codes
+				  WHERE
+					phone_number = ?
+					AND created_at > ?;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, phoneNumber, since).Scan(&count)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository CountOTPsSince", "Failed to count OTPs", map[string]string{
+			"phoneNumber": phoneNumber,
+		})
+		return 0, res.Error
+	}
+
+	return count, nil
+}
+
+// LockPhoneNumber serialises code requests of the phone number until the
+// surrounding transaction ends, so that counting and issuing a code cannot
+// interleave with another request. It must run inside a transaction.
+func (r *otpRepository) LockPhoneNumber(ctx context.Context, phoneNumber string) error {
+
+	logger.InfoContext(ctx, "otpRepository LockPhoneNumber", "Executing LockPhoneNumber SQL query", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	sqlScript := `SELECT pg_advisory_xact_lock(hashtextextended(?, 0));`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, "otp:"+phoneNumber)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository LockPhoneNumber", "Failed to lock phone number", map[string]string{
+			"phoneNumber": phoneNumber,
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+// ConsumeOTP marks the code as used and reports whether it was still unused,
+// so that only one of several parallel verifications of a code succeeds.
+func (r *otpRepository) ConsumeOTP(ctx context.Context, otpId uuid.UUID) (bool, error) {
+
+	logger.InfoContext(ctx, "otpRepository ConsumeOTP", "Executing ConsumeOTP SQL query", map[string]string{
+		"otpId": otpId.String(),
+	})
+
+	var consumed []uuid.UUID
+
+	sqlScript := `UPDATE otp_codes
+				  SET consumed_at = ?
+				  WHERE id = ? AND consumed_at IS NULL
+				  RETURNING id;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, time.Now(), otpId).Scan(&consumed)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository ConsumeOTP", "Failed to consume OTP", map[string]string{
+			"otpId": otpId.String(),
+		})
+		return false, res.Error
+	}
+
+	return len(consumed) > 0, nil
+}
+
+// ConsumeOTPs marks every outstanding code of the phone number as used, so
+// that requesting a new code or a successful login revokes the older ones.
+func (r *otpRepository) ConsumeOTPs(ctx context.Context, phoneNumber string) error {
+
+	logger.InfoContext(ctx, "otpRepository ConsumeOTPs", "Executing ConsumeOTPs SQL query", map[string]string{
+		"phoneNumber": phoneNumber,
+	})
+
+	sqlScript := `UPDATE otp_codes
+				  SET consumed_at = ?
+				  WHERE
+					phone_number = ?
+					AND consumed_at IS NULL;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, time.Now(), phoneNumber)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "otpRepository ConsumeOTPs", "Failed to consume OTPs", map[string]string{
+			"phoneNumber": phoneNumber,
+		})
+		return res.Error
+	}
+
+	return nil
+}
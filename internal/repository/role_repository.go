@@ -0,0 +1,347 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RoleRepository interface {
+	WithTx(tx *gorm.DB) RoleRepository
+	SearchRolesByUserId(ctx context.Context, userId uuid.UUID) ([]string, error)
+	SearchRoleRecordsByUserId(ctx context.Context, userId uuid.UUID) ([]model.Role, error)
+	ReplaceRoles(ctx context.Context, userId uuid.UUID, source string, roles []string) error
+	AddRoleMember(ctx context.Context, userId uuid.UUID, role string, source string) error
+	RemoveRoleMember(ctx context.Context, userId uuid.UUID, role string) error
+	SearchRoleMembers(ctx context.Context, role string) ([]model.User, error)
+	ListRoles(ctx context.Context, name string, offset int, limit int) ([]model.Role, int64, error)
+	SearchRoleById(ctx context.Context, roleId uuid.UUID) (*model.Role, error)
+	CreateRole(ctx context.Context, name string) (*model.Role, error)
+	RenameRole(ctx context.Context, roleId uuid.UUID, oldName string, newName string) error
+	DeleteRole(ctx context.Context, roleId uuid.UUID, name string) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: db,
+	}
+}
+
+func (r roleRepository) WithTx(tx *gorm.DB) RoleRepository {
+	return &roleRepository{
+		db: tx,
+	}
+}
+
+func (r *roleRepository) SearchRolesByUserId(ctx context.Context, userId uuid.UUID) ([]string, error) {
+
+	logger.InfoContext(ctx, "roleRepository SearchRolesByUserId", "Executing SearchRolesByUserId SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	roles := []string{}
+
+	sqlScript := `SELECT DISTINCT "role"
+				  FROM
+					user_roles
+				  WHERE
+					user_id = ?
+				  ORDER BY "role";`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(&roles)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository SearchRolesByUserId", "Failed to search roles", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return roles, nil
+}
+
+// SearchRoleRecordsByUserId returns the granted roles that exist in the
+// roles table, i.e. those managed as groups.
+func (r *roleRepository) SearchRoleRecordsByUserId(ctx context.Context, userId uuid.UUID) ([]model.Role, error) {
+
+	logger.InfoContext(ctx, "roleRepository SearchRoleRecordsByUserId", "Executing SearchRoleRecordsByUserId SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	resultModel := []model.Role{}
+
+	sqlScript := `SELECT DISTINCT r.id, r."name", r.created_at, r.updated_at
+				  FROM
+					roles r
+					JOIN user_roles ur ON ur."role" = r."name"
+				  WHERE
+					ur.user_id = ?
+				  ORDER BY r."name";`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(&resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository SearchRoleRecordsByUserId", "Failed to search roles", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// ReplaceRoles sets the roles granted to the user by source, leaving roles
+// from other sources untouched.
+func (r *roleRepository) ReplaceRoles(ctx context.Context, userId uuid.UUID, source string, roles []string) error {
+
+	logger.InfoContext(ctx, "roleRepository ReplaceRoles", "Executing ReplaceRoles SQL query", map[string]string{
+		"userId": userId.String(),
+		"source": source,
+	})
+
+	res := r.db.WithContext(ctx).Exec(`DELETE FROM user_roles WHERE user_id = ? AND "source" = ?;`, userId, source)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository ReplaceRoles", "Failed to delete roles", map[string]string{
+			"userId": userId.String(),
+			"source": source,
+		})
+		return res.Error
+	}
+
+	sqlScript := `INSERT INTO user_roles (user_id, "role", "source", created_at)
+				VALUES (?,?,?,?)
+				ON CONFLICT DO NOTHING;`
+
+	now := time.Now()
+
+	for _, role := range roles {
+		res := r.db.WithContext(ctx).Exec(sqlScript, userId, role, source, now)
+		if res.Error != nil {
+			logger.ErrorContext(ctx, "roleRepository ReplaceRoles", "Failed to insert role", map[string]string{
+				"userId": userId.String(),
+				"role":   role,
+			})
+			return res.Error
+		}
+	}
+
+	return nil
+}
+
+func (r *roleRepository) AddRoleMember(ctx context.Context, userId uuid.UUID, role string, source string) error {
+
+	logger.InfoContext(ctx, "roleRepository AddRoleMember", "Executing AddRoleMember SQL query", map[string]string{
+		"userId": userId.String(),
+		"role":   role,
+	})
+
+	sqlScript := `INSERT INTO user_roles (user_id, "role", "source", created_at)
+				VALUES (?,?,?,?)
+				ON CONFLICT DO NOTHING;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, userId, role, source, time.Now())
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository AddRoleMember", "Failed to add role member", map[string]string{
+			"userId": userId.String(),
+			"role":   role,
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+// RemoveRoleMember revokes the role from the user regardless of which source
+// granted it.
+func (r *roleRepository) RemoveRoleMember(ctx context.Context, userId uuid.UUID, role string) error {
+
+	logger.InfoContext(ctx, "roleRepository RemoveRoleMember", "Executing RemoveRoleMember SQL query", map[string]string{
+		"userId": userId.String(),
+		"role":   role,
+	})
+
+	res := r.db.WithContext(ctx).Exec(`DELETE FROM user_roles WHERE user_id = ? AND "role" = ?;`, userId, role)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository RemoveRoleMember", "Failed to remove role member", map[string]string{
+			"userId": userId.String(),
+			"role":   role,
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+func (r *roleRepository) SearchRoleMembers(ctx context.Context, role string) ([]model.User, error) {
+
+	logger.InfoContext(ctx, "roleRepository SearchRoleMembers", "Executing SearchRoleMembers SQL query", map[string]string{
+		"role": role,
+	})
+
+	resultModel := []model.User{}
+
+	sqlScript := `SELECT DISTINCT u.id, u.username
+				  FROM
+					user_roles ur
+					JOIN users u ON u.id = ur.user_id
+				  WHERE
+					ur."role" = ?
+				  ORDER BY u.username;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, role).Scan(&resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository SearchRoleMembers", "Failed to search role members", map[string]string{
+			"role": role,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// ListRoles returns a page of roles, optionally restricted to an exact name,
+// together with the total number of matches.
+func (r *roleRepository) ListRoles(ctx context.Context, name string, offset int, limit int) ([]model.Role, int64, error) {
+
+	logger.InfoContext(ctx, "roleRepository ListRoles", "Executing ListRoles SQL query", map[string]string{
+		"name":   name,
+		"offset": fmt.Sprint(offset),
+		"limit":  fmt.Sprint(limit),
+	})
+
+	where := ""
+	args := []interface{}{}
+	if name != "" {
+		where = ` WHERE lower("name") = lower(?)`
+		args = append(args, name)
+	}
+
+	var total int64
+
+	res := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM roles`+where+`;`, args...).Scan(&total)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository ListRoles", "Failed to count roles", map[string]string{
+			"error": res.Error.Error(),
+		})
+		return nil, 0, res.Error
+	}
+
+	resultModel := []model.Role{}
+
+	sqlScript := `SELECT id, "name", created_at, updated_at FROM roles` + where + ` ORDER BY "name" OFFSET ? LIMIT ?;`
+
+	res = r.db.WithContext(ctx).Raw(sqlScript, append(args, offset, limit)...).Scan(&resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository ListRoles", "Failed to list roles", map[string]string{
+			"error": res.Error.Error(),
+		})
+		return nil, 0, res.Error
+	}
+
+	return resultModel, total, nil
+}
+
+func (r *roleRepository) SearchRoleById(ctx context.Context, roleId uuid.UUID) (*model.Role, error) {
+
+	logger.InfoContext(ctx, "roleRepository SearchRoleById", "Executing SearchRoleById SQL query", map[string]string{
+		"roleId": roleId.String(),
+	})
+
+	resultModel := &model.Role{}
+
+	res := r.db.WithContext(ctx).Raw(`SELECT id, "name", created_at, updated_at FROM roles WHERE id = ?;`, roleId).Scan(resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository SearchRoleById", "Failed to search role", map[string]string{
+			"roleId": roleId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+func (r *roleRepository) CreateRole(ctx context.Context, name string) (*model.Role, error) {
+
+	logger.InfoContext(ctx, "roleRepository CreateRole", "Executing CreateRole SQL query", map[string]string{
+		"name": name,
+	})
+
+	now := time.Now()
+	resultModel := &model.Role{}
+
+	sqlScript := `INSERT INTO roles ("name", created_at, updated_at)
+				VALUES (?,?,?)
+				RETURNING id, "name", created_at, updated_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, name, now, now).Scan(resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository CreateRole", "Failed to create role", map[string]string{
+			"name": name,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// RenameRole renames the role and every grant of it.
+func (r *roleRepository) RenameRole(ctx context.Context, roleId uuid.UUID, oldName string, newName string) error {
+
+	logger.InfoContext(ctx, "roleRepository RenameRole", "Executing RenameRole SQL query", map[string]string{
+		"roleId":  roleId.String(),
+		"newName": newName,
+	})
+
+	res := r.db.WithContext(ctx).Exec(`UPDATE roles SET "name" = ?, updated_at = ? WHERE id = ?;`, newName, time.Now(), roleId)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository RenameRole", "Failed to rename role", map[string]string{
+			"roleId": roleId.String(),
+		})
+		return res.Error
+	}
+
+	res = r.db.WithContext(ctx).Exec(`UPDATE user_roles SET "role" = ? WHERE "role" = ?;`, newName, oldName)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository RenameRole", "Failed to rename role grants", map[string]string{
+			"roleId": roleId.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+// DeleteRole deletes the role and revokes it from every user.
+func (r *roleRepository) DeleteRole(ctx context.Context, roleId uuid.UUID, name string) error {
+
+	logger.InfoContext(ctx, "roleRepository DeleteRole", "Executing DeleteRole SQL query", map[string]string{
+		"roleId": roleId.String(),
+	})
+
+	res := r.db.WithContext(ctx).Exec(`DELETE FROM user_roles WHERE "role" = ?;`, name)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository DeleteRole", "Failed to delete role grants", map[string]string{
+			"roleId": roleId.String(),
+		})
+		return res.Error
+	}
+
+	res = r.db.WithContext(ctx).Exec(`DELETE FROM roles WHERE id = ?;`, roleId)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "roleRepository DeleteRole", "Failed to delete role", map[string]string{
+			"roleId": roleId.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
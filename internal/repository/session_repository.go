@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type SessionRepository interface {
+	WithTx(tx *gorm.DB) SessionRepository
+	SearchSessionState(ctx context.Context, userId uuid.UUID) (*model.SessionState, error)
+	RevokeSessions(ctx context.Context, userId uuid.UUID) error
+}
+
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		db: db,
+	}
+}
+
+func (r sessionRepository) WithTx(tx *gorm.DB) SessionRepository {
+	return &sessionRepository{
+		db: tx,
+	}
+}
+
+func (r *sessionRepository) SearchSessionState(ctx context.Context, userId uuid.UUID) (*model.SessionState, error) {
+	resultModel := &model.SessionState{}
+
+	sqlScript := `SELECT id, deleted_at, sessions_revoked_at
+				  FROM
+					users
+				  WHERE
+					id = ?;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "sessionRepository SearchSessionState", "Failed to search session state", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+func (r *sessionRepository) RevokeSessions(ctx context.Context, userId uuid.UUID) error {
+
+	logger.InfoContext(ctx, "sessionRepository RevokeSessions", "Executing RevokeSessions SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	sqlScript := `UPDATE users
+				  SET sessions_revoked_at = ?
+				  WHERE id = ?;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, time.Now(), userId)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "sessionRepository RevokeSessions", "Failed to revoke sessions", map[string]string{
+			"userId": userId.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
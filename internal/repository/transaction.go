@@ -1,21 +1,206 @@
 package repository
 
 import (
-	dbstore "github.com/EputraP/kfc_be/internal/store"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/util/logger"
 	"gorm.io/gorm"
 )
 
-type TransactionFunc func(tx *gorm.DB) error
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateUniqueViolation      = "23505"
+
+	defaultTxMaxRetries = 3
+)
+
+// TransactionFunc receives the transaction and a context carrying it. Passing
+// that context to TxManager.Do again nests the call in a savepoint.
+type TransactionFunc func(ctx context.Context, tx *gorm.DB) error
+
+type TxManager interface {
+	Do(ctx context.Context, transactionFn TransactionFunc, opts ...TxOption) error
+}
+
+type txOptions struct {
+	sqlOptions sql.TxOptions
+	maxRetries int
+}
+
+type TxOption func(*txOptions)
+
+// WithIsolation sets the isolation level of the outermost transaction. It is
+// ignored for nested calls.
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *txOptions) {
+		o.sqlOptions.Isolation = level
+	}
+}
+
+func WithReadOnly() TxOption {
+	return func(o *txOptions) {
+		o.sqlOptions.ReadOnly = true
+	}
+}
+
+// WithMaxRetries sets how many times a transaction failing with a
+// serialization failure or deadlock is retried.
+func WithMaxRetries(maxRetries int) TxOption {
+	return func(o *txOptions) {
+		o.maxRetries = maxRetries
+	}
+}
+
+type txState struct {
+	tx        *gorm.DB
+	depth     int
+	callbacks []func()
+}
+
+type txContextKey struct{}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{
+		db: db,
+	}
+}
+
+// Do runs transactionFn in a transaction, or in a savepoint when ctx already
+// carries one. The transaction is rolled back when transactionFn returns an
+// error or panics, and retried with backoff on SQLSTATE 40001 and 40P01.
+func (m *txManager) Do(ctx context.Context, transactionFn TransactionFunc, opts ...TxOption) error {
+	if parent, ok := ctx.Value(txContextKey{}).(*txState); ok {
+		return m.nested(ctx, parent, transactionFn)
+	}
+
+	options := txOptions{maxRetries: defaultTxMaxRetries}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var state *txState
+
+		state, err = m.run(ctx, transactionFn, &options.sqlOptions)
+		if err == nil {
+			for _, callback := range state.callbacks {
+				callback()
+			}
+			return nil
+		}
+
+		if !isRetryable(err) || attempt >= options.maxRetries {
+			return err
+		}
 
-func AsTransaction(transactionFn TransactionFunc) error {
-	db := dbstore.Get()
-	tx := db.Begin()
+		logger.WarnContext(ctx, "txManager Do", "Retrying transaction", map[string]string{
+			"attempt": fmt.Sprint(attempt + 1),
+			"error":   err.Error(),
+		})
 
-	if err := transactionFn(tx); err != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt+1) * 20 * time.Millisecond):
+		}
+	}
+}
+
+func (m *txManager) run(ctx context.Context, transactionFn TransactionFunc, sqlOptions *sql.TxOptions) (state *txState, err error) {
+	tx := m.db.WithContext(ctx).Begin(sqlOptions)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	state = &txState{tx: tx}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := transactionFn(context.WithValue(ctx, txContextKey{}, state), tx); err != nil {
 		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func (m *txManager) nested(ctx context.Context, parent *txState, transactionFn TransactionFunc) (err error) {
+	savepoint := fmt.Sprintf("sp_%d", parent.depth+1)
+
+	if err := parent.tx.SavePoint(savepoint).Error; err != nil {
 		return err
 	}
 
-	tx.Commit()
+	state := &txState{tx: parent.tx, depth: parent.depth + 1}
+
+	defer func() {
+		if p := recover(); p != nil {
+			parent.tx.RollbackTo(savepoint)
+			panic(p)
+		}
+	}()
+
+	if err := transactionFn(context.WithValue(ctx, txContextKey{}, state), parent.tx); err != nil {
+		if rollbackErr := parent.tx.RollbackTo(savepoint).Error; rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+
+	// Hooks of a savepoint only run if the outermost transaction commits
+	parent.callbacks = append(parent.callbacks, state.callbacks...)
+
 	return nil
 }
+
+// AfterCommit registers callback to run once the transaction carried by ctx
+// commits. It runs immediately when ctx carries no transaction.
+func AfterCommit(ctx context.Context, callback func()) {
+	state, ok := ctx.Value(txContextKey{}).(*txState)
+	if !ok {
+		callback()
+		return
+	}
+
+	state.callbacks = append(state.callbacks, callback)
+}
+
+func isRetryable(err error) bool {
+	code := sqlState(err)
+
+	return code == sqlStateSerializationFailure || code == sqlStateDeadlockDetected
+}
+
+func isUniqueViolation(err error) bool {
+	return sqlState(err) == sqlStateUniqueViolation
+}
+
+// sqlState returns the SQLSTATE code of a database error, or "" when err
+// does not carry one.
+func sqlState(err error) string {
+	var sqlStateErr interface{ SQLState() string }
+	if !errors.As(err, &sqlStateErr) {
+		return ""
+	}
+
+	return sqlStateErr.SQLState()
+}
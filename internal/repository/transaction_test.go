@@ -0,0 +1,246 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// sqlStateError stands in for the pgx error carrying a SQLSTATE code.
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+var errFailed = errors.New("failed")
+
+func TestTxManagerDo(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []repository.TxOption
+		// expect records the statements the database should receive
+		expect func(mock sqlmock.Sqlmock)
+		// fn runs inside the transaction on every attempt, starting at 1.
+		// hook counts the after-commit hooks that ran.
+		fn        func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error
+		wantErr   error
+		wantCalls int
+		wantHooks int
+	}{
+		{
+			name: "commits and runs hooks",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return nil
+			},
+			wantCalls: 1,
+			wantHooks: 1,
+		},
+		{
+			name: "rolls back on error without running hooks",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return errFailed
+			},
+			wantErr:   errFailed,
+			wantCalls: 1,
+		},
+		{
+			name: "rolls back a failed nested call to its savepoint",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				err := m.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+					// Dropped with the savepoint
+					repository.AfterCommit(ctx, hook)
+					return errFailed
+				})
+				if !errors.Is(err, errFailed) {
+					return err
+				}
+				return nil
+			},
+			wantCalls: 1,
+			wantHooks: 1,
+		},
+		{
+			name: "runs hooks of a nested call after the outer commit",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return m.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+					repository.AfterCommit(ctx, hook)
+					return nil
+				})
+			},
+			wantCalls: 1,
+			wantHooks: 2,
+		},
+		{
+			name: "retries a serialization failure",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				if attempt == 1 {
+					return sqlStateError("40001")
+				}
+				return nil
+			},
+			wantCalls: 2,
+			wantHooks: 1,
+		},
+		{
+			name: "retries a deadlock",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectCommit()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				if attempt == 1 {
+					return sqlStateError("40P01")
+				}
+				return nil
+			},
+			wantCalls: 2,
+			wantHooks: 1,
+		},
+		{
+			name: "gives up after the maximum retries",
+			opts: []repository.TxOption{repository.WithMaxRetries(1)},
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return sqlStateError("40001")
+			},
+			wantErr:   sqlStateError("40001"),
+			wantCalls: 2,
+		},
+		{
+			name: "does not retry other database errors",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectRollback()
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return sqlStateError("23505")
+			},
+			wantErr:   sqlStateError("23505"),
+			wantCalls: 1,
+		},
+		{
+			name: "does not run hooks when the commit fails",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectCommit().WillReturnError(errFailed)
+			},
+			fn: func(ctx context.Context, m repository.TxManager, attempt int, hook func()) error {
+				return nil
+			},
+			wantErr:   errFailed,
+			wantCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, mock := newTxManager(t)
+			tt.expect(mock)
+
+			calls, hooks := 0, 0
+			hook := func() {
+				// Every expected statement, the commit included, ran first
+				if err := mock.ExpectationsWereMet(); err != nil {
+					t.Errorf("hook ran before the commit: %v", err)
+				}
+				hooks++
+			}
+
+			err := m.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+				calls++
+				repository.AfterCommit(ctx, hook)
+
+				return tt.fn(ctx, m, calls, hook)
+			}, tt.opts...)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Do() error = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("transaction function ran %d times, want %d", calls, tt.wantCalls)
+			}
+			if hooks != tt.wantHooks {
+				t.Errorf("hooks ran %d times, want %d", hooks, tt.wantHooks)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestTxManagerDoRollsBackOnPanic(t *testing.T) {
+	m, mock := newTxManager(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	defer func() {
+		if p := recover(); p == nil {
+			t.Error("Do() did not re-panic")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	_ = m.Do(context.Background(), func(ctx context.Context, tx *gorm.DB) error {
+		panic("boom")
+	})
+}
+
+func newTxManager(t *testing.T) (repository.TxManager, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: gormlogger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return repository.NewTxManager(db), mock
+}
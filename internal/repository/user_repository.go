@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type UserRepository interface {
+	WithTx(tx *gorm.DB) UserRepository
+	ListUsers(ctx context.Context, filter *model.UserFilter, offset int, limit int) ([]model.UserAccount, int64, error)
+	SearchUserAccountById(ctx context.Context, userId uuid.UUID) (*model.UserAccount, error)
+	CreateUserAccount(ctx context.Context, input *model.UserAccount, hashedPassword string) (*model.UserAccount, error)
+	UpdateUserAccount(ctx context.Context, input *model.UserAccount) error
+	SetUserActive(ctx context.Context, userId uuid.UUID, active bool) error
+	UpdatePassword(ctx context.Context, userId uuid.UUID, hashedPassword string) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{
+		db: db,
+	}
+}
+
+func (r userRepository) WithTx(tx *gorm.DB) UserRepository {
+	return &userRepository{
+		db: tx,
+	}
+}
+
+const userAccountSelect = `SELECT u.id, u.username, COALESCE(u.external_id, '') AS external_id,
+					COALESCE(ud.email, '') AS email, COALESCE(ud.phone_number, '') AS phone_number,
+					COALESCE(ud."address", '') AS "address", u.created_at, u.updated_at, u.deleted_at
+				  FROM
+					users u
+					LEFT JOIN user_details ud ON ud.user_id = u.id AND ud.deleted_at IS NULL`
+
+// ListUsers returns a page of users, including deactivated ones, together
+// with the total number of matches.
+func (r *userRepository) ListUsers(ctx context.Context, filter *model.UserFilter, offset int, limit int) ([]model.UserAccount, int64, error) {
+
+	logger.InfoContext(ctx, "userRepository ListUsers", "Executing ListUsers SQL query", map[string]string{
+		"offset": fmt.Sprint(offset),
+		"limit":  fmt.Sprint(limit),
+	})
+
+	where, args := userFilterClause(filter)
+
+	var total int64
+	countScript := `SELECT COUNT(*) FROM (` + userAccountSelect + where + `) AS filtered;`
+
+	res := r.db.WithContext(ctx).Raw(countScript, args...).Scan(&total)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository ListUsers", "Failed to count users", map[string]string{
+			"error": res.Error.Error(),
+		})
+		return nil, 0, res.Error
+	}
+
+	resultModel := []model.UserAccount{}
+	sqlScript := userAccountSelect + where + ` ORDER BY u.created_at, u.id OFFSET ? LIMIT ?;`
+
+	res = r.db.WithContext(ctx).Raw(sqlScript, append(args, offset, limit)...).Scan(&resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository ListUsers", "Failed to list users", map[string]string{
+			"error": res.Error.Error(),
+		})
+		return nil, 0, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository ListUsers", "Successfully ran ListUsers", map[string]string{
+		"total": fmt.Sprint(total),
+	})
+
+	return resultModel, total, nil
+}
+
+func (r *userRepository) SearchUserAccountById(ctx context.Context, userId uuid.UUID) (*model.UserAccount, error) {
+
+	logger.InfoContext(ctx, "userRepository SearchUserAccountById", "Executing SearchUserAccountById SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	resultModel := &model.UserAccount{}
+
+	res := r.db.WithContext(ctx).Raw(userAccountSelect+` WHERE u.id = ?;`, userId).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository SearchUserAccountById", "Failed to search user", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+func (r *userRepository) CreateUserAccount(ctx context.Context, input *model.UserAccount, hashedPassword string) (*model.UserAccount, error) {
+
+	logger.InfoContext(ctx, "userRepository CreateUserAccount", "Executing CreateUserAccount SQL query", map[string]string{
+		"username": input.Username,
+	})
+
+	now := time.Now()
+	resultModel := &model.UserAccount{}
+
+	sqlScript := `INSERT INTO users (username, password, external_id, created_at, updated_at)
+				VALUES (?,?,NULLIF(?, ''),?,?)
+				RETURNING id;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.Username, hashedPassword, input.ExternalId, now, now).Scan(resultModel)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository CreateUserAccount", "Failed to create user", map[string]string{
+			"username": input.Username,
+		})
+		if isUniqueViolation(res.Error) {
+			return nil, errs.UsernameAlreadyUsed
+		}
+		return nil, res.Error
+	}
+
+	detailScript := `INSERT INTO user_details (user_id, email, "address", phone_number, age, created_at, updated_at)
+				VALUES (?,?,?,?,0,?,?);`
+
+	res = r.db.WithContext(ctx).Exec(detailScript, resultModel.Id, input.Email, input.Address, input.PhoneNumber, now, now)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository CreateUserAccount", "Failed to create user detail", map[string]string{
+			"username": input.Username,
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "userRepository CreateUserAccount", "Successfully created user", map[string]string{
+		"username": input.Username,
+	})
+
+	return r.SearchUserAccountById(ctx, resultModel.Id)
+}
+
+func (r *userRepository) UpdateUserAccount(ctx context.Context, input *model.UserAccount) error {
+
+	logger.InfoContext(ctx, "userRepository UpdateUserAccount", "Executing UpdateUserAccount SQL query", map[string]string{
+		"userId": input.Id.String(),
+	})
+
+	now := time.Now()
+
+	sqlScript := `UPDATE users
+				  SET username = ?, external_id = NULLIF(?, ''), updated_at = ?
+				  WHERE id = ?;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, input.Username, input.ExternalId, now, input.Id)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository UpdateUserAccount", "Failed to update user", map[string]string{
+			"userId": input.Id.String(),
+		})
+		if isUniqueViolation(res.Error) {
+			return errs.UsernameAlreadyUsed
+		}
+		return res.Error
+	}
+
+	detailScript := `UPDATE user_details
+				  SET email = ?, "address" = ?, phone_number = ?, updated_at = ?
+				  WHERE user_id = ? AND deleted_at IS NULL;`
+
+	res = r.db.WithContext(ctx).Exec(detailScript, input.Email, input.Address, input.PhoneNumber, now, input.Id)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository UpdateUserAccount", "Failed to update user detail", map[string]string{
+			"userId": input.Id.String(),
+		})
+		return res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		insertScript := `INSERT INTO user_details (user_id, email, "address", phone_number, age, created_at, updated_at)
+				VALUES (?,?,?,?,0,?,?);`
+
+		res = r.db.WithContext(ctx).Exec(insertScript, input.Id, input.Email, input.Address, input.PhoneNumber, now, now)
+		if res.Error != nil {
+			logger.ErrorContext(ctx, "userRepository UpdateUserAccount", "Failed to create user detail", map[string]string{
+				"userId": input.Id.String(),
+			})
+			return res.Error
+		}
+	}
+
+	return nil
+}
+
+// SetUserActive clears or sets deleted_at. Deactivated users cannot log in
+// and their existing tokens are rejected.
+func (r *userRepository) SetUserActive(ctx context.Context, userId uuid.UUID, active bool) error {
+
+	logger.InfoContext(ctx, "userRepository SetUserActive", "Executing SetUserActive SQL query", map[string]string{
+		"userId": userId.String(),
+		"active": fmt.Sprint(active),
+	})
+
+	now := time.Now()
+
+	var deletedAt *time.Time
+	if !active {
+		deletedAt = &now
+	}
+
+	sqlScript := `UPDATE users
+				  SET deleted_at = ?, updated_at = ?
+				  WHERE id = ?;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, deletedAt, now, userId)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository SetUserActive", "Failed to update user", map[string]string{
+			"userId": userId.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, userId uuid.UUID, hashedPassword string) error {
+
+	logger.InfoContext(ctx, "userRepository UpdatePassword", "Executing UpdatePassword SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	sqlScript := `UPDATE users
+				  SET "password" = ?, updated_at = ?
+				  WHERE id = ?;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, hashedPassword, time.Now(), userId)
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "userRepository UpdatePassword", "Failed to update password", map[string]string{
+			"userId": userId.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+func userFilterClause(filter *model.UserFilter) (string, []interface{}) {
+	if filter == nil {
+		return "", nil
+	}
+
+	switch filter.Operator {
+	case "eq":
+		return fmt.Sprintf(" WHERE lower(%s) = lower(?)", filter.Column), []interface{}{filter.Value}
+	case "ne":
+		return fmt.Sprintf(" WHERE lower(%s) <> lower(?)", filter.Column), []interface{}{filter.Value}
+	case "co":
+		return fmt.Sprintf(" WHERE %s ILIKE ?", filter.Column), []interface{}{"%" + escapeLike(filter.Value) + "%"}
+	case "sw":
+		return fmt.Sprintf(" WHERE %s ILIKE ?", filter.Column), []interface{}{escapeLike(filter.Value) + "%"}
+	case "ew":
+		return fmt.Sprintf(" WHERE %s ILIKE ?", filter.Column), []interface{}{"%" + escapeLike(filter.Value)}
+	case "pr":
+		return fmt.Sprintf(" WHERE COALESCE(%s, '') <> ''", filter.Column), nil
+	case "null":
+		return fmt.Sprintf(" WHERE %s IS NULL", filter.Column), nil
+	case "notnull":
+		return fmt.Sprintf(" WHERE %s IS NOT NULL", filter.Column), nil
+	default:
+		return "", nil
+	}
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WebAuthnRepository interface {
+	WithTx(tx *gorm.DB) WebAuthnRepository
+	CreateCredential(ctx context.Context, input *model.WebAuthnCredential) (*model.WebAuthnCredential, error)
+	SearchCredentialsByUserId(ctx context.Context, userId uuid.UUID) ([]model.WebAuthnCredential, error)
+	UpdateCredentialUsage(ctx context.Context, input *model.WebAuthnCredential) error
+	CreateChallenge(ctx context.Context, input *model.WebAuthnChallenge) (*model.WebAuthnChallenge, error)
+	ConsumeChallenge(ctx context.Context, challengeId uuid.UUID, ceremony string) (*model.WebAuthnChallenge, error)
+}
+
+type webAuthnRepository struct {
+	db *gorm.DB
+}
+
+func NewWebAuthnRepository(db *gorm.DB) WebAuthnRepository {
+	return &webAuthnRepository{
+		db: db,
+	}
+}
+
+func (r webAuthnRepository) WithTx(tx *gorm.DB) WebAuthnRepository {
+	return &webAuthnRepository{
+		db: tx,
+	}
+}
+
+func (r *webAuthnRepository) CreateCredential(ctx context.Context, input *model.WebAuthnCredential) (*model.WebAuthnCredential, error) {
+
+	logger.InfoContext(ctx, "webAuthnRepository CreateCredential", "Executing CreateCredential SQL query", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	resultModel := &model.WebAuthnCredential{}
+
+	sqlScript := `INSERT INTO webauthn_credentials (user_id, credential_id, public_key, attestation_type, aaguid, sign_count,
+					transports, attachment, user_present, user_verified, backup_eligible, backup_state, created_at)
+				VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?)
+				RETURNING id, user_id, credential_id, attestation_type, sign_count;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.UserId, input.CredentialId, input.PublicKey, input.AttestationType, input.AAGUID, input.SignCount,
+		input.Transports, input.Attachment, input.UserPresent, input.UserVerified, input.BackupEligible, input.BackupState, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "webAuthnRepository CreateCredential", "Failed to create credential", map[string]string{
+			"userId": input.UserId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "webAuthnRepository CreateCredential", "Successfully created credential", map[string]string{
+		"userId": input.UserId.String(),
+	})
+
+	return resultModel, nil
+}
+
+func (r *webAuthnRepository) SearchCredentialsByUserId(ctx context.Context, userId uuid.UUID) ([]model.WebAuthnCredential, error) {
+
+	logger.InfoContext(ctx, "webAuthnRepository SearchCredentialsByUserId", "Executing SearchCredentialsByUserId SQL query", map[string]string{
+		"userId": userId.String(),
+	})
+
+	resultModel := []model.WebAuthnCredential{}
+
+	sqlScript := `SELECT id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, attachment,
+					user_present, user_verified, backup_eligible, backup_state, clone_warning, last_used_at
+				  FROM
+					webauthn_credentials
+				  WHERE
+					user_id = ?;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, userId).Scan(&resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "webAuthnRepository SearchCredentialsByUserId", "Failed to search credentials", map[string]string{
+			"userId": userId.String(),
+		})
+		return nil, res.Error
+	}
+
+	logger.InfoContext(ctx, "webAuthnRepository SearchCredentialsByUserId", "Successfully ran SearchCredentialsByUserId", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return resultModel, nil
+}
+
+// UpdateCredentialUsage stores the sign count, flags and clone warning
+// reported by the latest assertion.
+func (r *webAuthnRepository) UpdateCredentialUsage(ctx context.Context, input *model.WebAuthnCredential) error {
+
+	logger.InfoContext(ctx, "webAuthnRepository UpdateCredentialUsage", "Executing UpdateCredentialUsage SQL query", map[string]string{
+		"credentialId": input.Id.String(),
+	})
+
+	now := time.Now()
+
+	sqlScript := `UPDATE webauthn_credentials
+				  SET sign_count = ?, backup_state = ?, clone_warning = ?, last_used_at = ?, updated_at = ?
+				  WHERE id = ?;`
+
+	res := r.db.WithContext(ctx).Exec(sqlScript, input.SignCount, input.BackupState, input.CloneWarning, now, now, input.Id)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "webAuthnRepository UpdateCredentialUsage", "Failed to update credential", map[string]string{
+			"credentialId": input.Id.String(),
+		})
+		return res.Error
+	}
+
+	return nil
+}
+
+func (r *webAuthnRepository) CreateChallenge(ctx context.Context, input *model.WebAuthnChallenge) (*model.WebAuthnChallenge, error) {
+
+	logger.InfoContext(ctx, "webAuthnRepository CreateChallenge", "Executing CreateChallenge SQL query", map[string]string{
+		"ceremony": input.Ceremony,
+	})
+
+	resultModel := &model.WebAuthnChallenge{}
+
+	sqlScript := `INSERT INTO webauthn_challenges (user_id, ceremony, session_data, expires_at, created_at)
+				VALUES (?,?,?,?,?)
+				RETURNING id, user_id, ceremony, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, input.UserId, input.Ceremony, string(input.SessionData), input.ExpiresAt, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "webAuthnRepository CreateChallenge", "Failed to create challenge", map[string]string{
+			"ceremony": input.Ceremony,
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
+
+// ConsumeChallenge deletes an unexpired challenge and returns it, so every
+// challenge can be answered at most once. An empty model is returned when no
+// challenge matches.
+func (r *webAuthnRepository) ConsumeChallenge(ctx context.Context, challengeId uuid.UUID, ceremony string) (*model.WebAuthnChallenge, error) {
+
+	logger.InfoContext(ctx, "webAuthnRepository ConsumeChallenge", "Executing ConsumeChallenge SQL query", map[string]string{
+		"challengeId": challengeId.String(),
+	})
+
+	resultModel := &model.WebAuthnChallenge{}
+
+	sqlScript := `DELETE FROM webauthn_challenges
+				  WHERE
+					id = ?
+					AND ceremony = ?
+					AND expires_at > ?
+				  RETURNING id, user_id, ceremony, session_data, expires_at;`
+
+	res := r.db.WithContext(ctx).Raw(sqlScript, challengeId, ceremony, time.Now()).Scan(resultModel)
+
+	if res.Error != nil {
+		logger.ErrorContext(ctx, "webAuthnRepository ConsumeChallenge", "Failed to consume challenge", map[string]string{
+			"challengeId": challengeId.String(),
+		})
+		return nil, res.Error
+	}
+
+	return resultModel, nil
+}
@@ -0,0 +1,450 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/EputraP/kfc_be/internal/util/openapi"
+	"github.com/EputraP/kfc_be/internal/util/scim"
+)
+
+const (
+	OpenAPIPath = "/openapi.json"
+	DocsPath    = "/docs"
+	// DocsAssetsPath serves the Swagger UI files loaded by the docs page.
+	DocsAssetsPath = DocsPath + "/assets"
+
+	apiTitle = "kfc_be"
+)
+
+var (
+	// authenticated are the schemes middlewares.Auth accepts.
+	authenticated = []string{openapi.SecurityBearer, openapi.SecurityCookie}
+
+	// authErrors are the errors middlewares.Auth fails with.
+	authErrors = []*errs.AppError{errs.InvalidBearerFormat, errs.InvalidToken, errs.InvalidIssuer, errs.SessionRevoked, errs.InvalidCSRFToken, errs.InsufficientScope}
+
+	scimQuery = []openapi.Parameter{
+		{Name: "filter", Description: "SCIM filter, e.g. `userName eq \"jane\"`"},
+		{Name: "startIndex", Description: "1-based index of the first result"},
+		{Name: "count", Description: "Maximum number of results, 100 when left out and at most 200. 0 returns totalResults only"},
+	}
+)
+
+// Spec documents every route registered by Build for versions. The routes
+// package tests and the openapi subcommand fail when the two drift apart, so
+// a route added to Build must be added here too.
+func Spec(versions []apiversion.Version) *openapi.Document {
+	d := openapi.New(openapi.Info{
+		Title:       apiTitle,
+		Version:     "1.0.0",
+		Description: "Authentication and user management API. Responses use the `{code, msg, data}` envelope unless noted; errors carry a stable `errorCode`.",
+	},
+		openapi.Tag{Name: "Auth", Description: "Registration, login and tokens"},
+		openapi.Tag{Name: "WebAuthn", Description: "Passkeys"},
+		openapi.Tag{Name: "OAuth", Description: "Login with external identity providers"},
+		openapi.Tag{Name: "Organizations"},
+		openapi.Tag{Name: "Admin", Description: "Admin only"},
+		openapi.Tag{Name: "SCIM", Description: "SCIM 2.0 provisioning, in application/scim+json"},
+		openapi.Tag{Name: "Operations", Description: "Probes, metrics and these docs"},
+	)
+
+	addOperationsSpec(d)
+	for _, version := range versions {
+		s := versionSpec{d: d, version: version}
+		addAuthSpec(s)
+		addOrganizationSpec(s)
+		addAdminSpec(s)
+	}
+	addScimSpec(d)
+
+	return d
+}
+
+// versionSpec adds the operations of one version under its prefix,
+// deprecated when the version is.
+type versionSpec struct {
+	d       *openapi.Document
+	version apiversion.Version
+}
+
+func (s versionSpec) Add(op openapi.Operation) {
+	op.Path = s.version.Prefix() + op.Path
+	op.Deprecated = s.version.Deprecated()
+	if op.Deprecated && s.version.Successor != "" {
+		op.Description = strings.TrimSpace(fmt.Sprintf("Deprecated alias of `%s`. %s", apiversion.Prefix(s.version.Successor)+strings.TrimPrefix(op.Path, s.version.Prefix()), op.Description))
+	}
+
+	s.d.Add(op)
+}
+
+func addOperationsSpec(d *openapi.Document) {
+	d.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/healthz",
+		Tag:      "Operations",
+		Summary:  "Liveness probe",
+		Response: dto.HealthResponse{},
+	})
+	d.Add(openapi.Operation{
+		Method:      http.MethodGet,
+		Path:        "/readyz",
+		Tag:         "Operations",
+		Summary:     "Readiness probe with per-dependency checks",
+		Description: "Answers 503 with the same body when a dependency is down or the server is draining.",
+		Response:    dto.HealthResponse{},
+	})
+	d.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     OpenAPIPath,
+		Tag:      "Operations",
+		Summary:  "This OpenAPI document",
+		Raw:      true,
+		Response: openapi.Object("OpenAPI 3.1 document"),
+	})
+	d.Add(openapi.Operation{
+		Method:       http.MethodGet,
+		Path:         DocsPath,
+		Tag:          "Operations",
+		Summary:      "Interactive API docs",
+		Raw:          true,
+		Response:     &openapi.Schema{Type: "string"},
+		ResponseType: "text/html",
+	})
+	d.Add(openapi.Operation{
+		Method:       http.MethodGet,
+		Path:         DocsAssetsPath + "/:asset",
+		Tag:          "Operations",
+		Summary:      "Swagger UI assets of the API docs",
+		Raw:          true,
+		Response:     &openapi.Schema{Type: "string"},
+		ResponseType: "text/plain",
+	})
+}
+
+func addAuthSpec(s versionSpec) {
+	// Unversioned clients are not held to the username rules
+	var registerBody interface{} = dto.RegisterBodyV1{}
+	if s.version.Name == apiversion.Unversioned {
+		registerBody = dto.RegisterBody{}
+	}
+
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/register",
+		Tag:         "Auth",
+		Summary:     "Create user account",
+		RequestBody: registerBody,
+		Status:      http.StatusCreated,
+		Response:    dto.RegisterResponse{},
+		Errors:      []*errs.AppError{errs.ValidationFailed, errs.UsernameAlreadyUsed, errs.PasswordContainUsername},
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/login",
+		Tag:         "Auth",
+		Summary:     "Login user",
+		Description: "Also sets the token cookies when cookie auth is enabled.",
+		RequestBody: dto.LoginBody{},
+		Response:    dto.LoginResponse{},
+		Errors:      []*errs.AppError{errs.ValidationFailed, errs.UsernamePasswordIncorrect, errs.DirectoryAccountNotLinked, errs.DirectoryUnavailable},
+	})
+	// Unversioned clients get the bare token
+	var refreshResponse interface{} = dto.RefreshTokenResponse{}
+	if s.version.Name == apiversion.Unversioned {
+		refreshResponse = &openapi.Schema{Type: "string", Description: "The new access token"}
+	}
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/refresh",
+		Tag:         "Auth",
+		Summary:     "Renew access token",
+		Description: "Authenticated with the refresh token, as bearer token or cookie. Cookie requests must echo the `XSRF-TOKEN` cookie in the `X-XSRF-TOKEN` header.",
+		Security:    authenticated,
+		Response:    refreshResponse,
+		Errors:      authErrors,
+	})
+	s.Add(openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    "/auth/logout",
+		Tag:     "Auth",
+		Summary: "Logout",
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/magic-link",
+		Tag:         "Auth",
+		Summary:     "Email a single-use sign-in link",
+		Description: "Answers the same whether or not the email is registered.",
+		RequestBody: dto.MagicLinkBody{},
+		Errors:      []*errs.AppError{errs.ValidationFailed},
+	})
+	s.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/auth/magic-link/callback",
+		Tag:      "Auth",
+		Summary:  "Exchange a sign-in link for tokens",
+		Query:    []openapi.Parameter{{Name: "token", Description: "Token of the emailed link", Required: true}},
+		Response: dto.LoginResponse{},
+		Errors:   []*errs.AppError{errs.InvalidMagicLink},
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/otp/request",
+		Tag:         "Auth",
+		Summary:     "Send a login code by SMS",
+		RequestBody: dto.OTPRequestBody{},
+		Errors:      []*errs.AppError{errs.ValidationFailed, errs.OTPRequestsExceeded, errs.SendOTPError},
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/otp/verify",
+		Tag:         "Auth",
+		Summary:     "Log in or register with an SMS code",
+		RequestBody: dto.OTPVerifyBody{},
+		Response:    dto.LoginResponse{},
+		Errors:      []*errs.AppError{errs.ValidationFailed, errs.InvalidOTP, errs.OTPAttemptsExceeded, errs.AccountDeactivated},
+	})
+
+	webAuthnOptions := "WebAuthn options, passed as is to navigator.credentials"
+	webAuthnCredential := "Credential returned by navigator.credentials"
+	s.Add(openapi.Operation{
+		Method:   http.MethodPost,
+		Path:     "/auth/webauthn/register/begin",
+		Tag:      "WebAuthn",
+		Summary:  "Start passkey registration",
+		Security: authenticated,
+		Response: openapi.Object(webAuthnOptions),
+		Errors:   append([]*errs.AppError{errs.WebAuthnDisabled}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/webauthn/register/finish",
+		Tag:         "WebAuthn",
+		Summary:     "Store a new passkey",
+		Security:    authenticated,
+		RequestBody: openapi.Object(webAuthnCredential),
+		Status:      http.StatusCreated,
+		Errors:      append([]*errs.AppError{errs.WebAuthnDisabled, errs.InvalidWebAuthnSession, errs.InvalidRequestBody, errs.WebAuthnVerificationFailed}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:       http.MethodPost,
+		Path:         "/auth/webauthn/login/begin",
+		Tag:          "WebAuthn",
+		Summary:      "Start passkey login",
+		Description:  "Without a username a discoverable (passkey) login is started.",
+		RequestBody:  dto.WebAuthnLoginBeginBody{},
+		OptionalBody: true,
+		Response:     openapi.Object(webAuthnOptions),
+		Errors:       []*errs.AppError{errs.WebAuthnDisabled, errs.WebAuthnNoCredentials},
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/auth/webauthn/login/finish",
+		Tag:         "WebAuthn",
+		Summary:     "Log in with a passkey",
+		RequestBody: openapi.Object(webAuthnCredential),
+		Response:    dto.LoginResponse{},
+		Errors:      []*errs.AppError{errs.WebAuthnDisabled, errs.InvalidWebAuthnSession, errs.InvalidRequestBody, errs.WebAuthnVerificationFailed, errs.WebAuthnCloneDetected},
+	})
+
+	s.Add(openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    "/auth/oauth/:provider/start",
+		Tag:     "OAuth",
+		Summary: "Redirect to an external identity provider",
+		Status:  http.StatusFound,
+		Raw:     true,
+		Errors:  []*errs.AppError{errs.OAuthProviderNotFound, errs.OAuthProviderUnavailable},
+	})
+	s.Add(openapi.Operation{
+		Method:  http.MethodGet,
+		Path:    "/auth/oauth/:provider/callback",
+		Tag:     "OAuth",
+		Summary: "Log in with an external identity",
+		Query: []openapi.Parameter{
+			{Name: "code", Description: "Authorization code"},
+			{Name: "state", Description: "State of the start redirect"},
+			{Name: "error", Description: "Error reported by the provider"},
+		},
+		Response: dto.LoginResponse{},
+		Errors:   []*errs.AppError{errs.OAuthProviderNotFound, errs.InvalidOAuthState, errs.OAuthExchangeFailed, errs.AccountDeactivated},
+	})
+}
+
+func addOrganizationSpec(s versionSpec) {
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/orgs",
+		Tag:         "Organizations",
+		Summary:     "Create an organization owned by the caller",
+		Security:    authenticated,
+		RequestBody: dto.CreateOrganizationBody{},
+		Status:      http.StatusCreated,
+		Response:    model.Organization{},
+		Errors:      append([]*errs.AppError{errs.ValidationFailed}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/orgs",
+		Tag:      "Organizations",
+		Summary:  "List the caller's organizations",
+		Security: authenticated,
+		Response: []model.Organization{},
+		Errors:   authErrors,
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/orgs/switch",
+		Tag:         "Organizations",
+		Summary:     "Re-issue tokens for another active organization",
+		Security:    authenticated,
+		RequestBody: dto.SwitchOrganizationBody{},
+		Response:    dto.LoginResponse{},
+		Errors:      append([]*errs.AppError{errs.ValidationFailed, errs.OrganizationNotMember}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/orgs/invitations/accept",
+		Tag:         "Organizations",
+		Summary:     "Join an organization with an invitation token",
+		Security:    authenticated,
+		RequestBody: dto.AcceptInvitationBody{},
+		Response:    model.Membership{},
+		Errors:      append([]*errs.AppError{errs.ValidationFailed, errs.InvalidInvitation}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/orgs/current/members",
+		Tag:      "Organizations",
+		Summary:  "List members of the active organization",
+		Security: authenticated,
+		Response: []model.Membership{},
+		Errors:   append([]*errs.AppError{errs.OrganizationRequired}, authErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/orgs/current/invitations",
+		Tag:         "Organizations",
+		Summary:     "Invite a member by email (owner/admin)",
+		Security:    authenticated,
+		RequestBody: dto.InviteMemberBody{},
+		Status:      http.StatusCreated,
+		Response:    model.OrganizationInvitation{},
+		Errors:      append([]*errs.AppError{errs.ValidationFailed, errs.OrganizationRequired, errs.ForbiddenAccess, errs.InvalidOrganizationRole, errs.SendInvitationError}, authErrors...),
+	})
+}
+
+func addAdminSpec(s versionSpec) {
+	adminErrors := append([]*errs.AppError{errs.ForbiddenAccess}, authErrors...)
+
+	s.Add(openapi.Operation{
+		Method:      http.MethodPost,
+		Path:        "/admin/users/:id/impersonate",
+		Tag:         "Admin",
+		Summary:     "Issue a short-lived token acting as a user",
+		Security:    authenticated,
+		RequestBody: dto.ImpersonateBody{},
+		Response:    dto.ImpersonateResponse{},
+		Errors:      append([]*errs.AppError{errs.InvalidIDParam, errs.ValidationFailed, errs.ImpersonationTargetNotFound, errs.ImpersonationNotAllowed, errs.InvalidImpersonationScope}, adminErrors...),
+	})
+	s.Add(openapi.Operation{
+		Method:   http.MethodGet,
+		Path:     "/admin/log-level",
+		Tag:      "Admin",
+		Summary:  "Read the log level",
+		Security: authenticated,
+		Response: dto.LogLevelResponse{},
+		Errors:   adminErrors,
+	})
+	s.Add(openapi.Operation{
+		Method:      http.MethodPut,
+		Path:        "/admin/log-level",
+		Tag:         "Admin",
+		Summary:     "Change the log level at runtime",
+		Security:    authenticated,
+		RequestBody: dto.LogLevelBody{},
+		Response:    dto.LogLevelResponse{},
+		Errors:      append([]*errs.AppError{errs.ValidationFailed, errs.InvalidLogLevel}, adminErrors...),
+	})
+}
+
+func addScimSpec(d *openapi.Document) {
+	resources := []struct {
+		path     string
+		name     string
+		resource interface{}
+	}{
+		{"/scim/v2/Users", "user", scim.User{}},
+		{"/scim/v2/Groups", "group", scim.Group{}},
+	}
+
+	for _, r := range resources {
+		d.Add(scimOperation(openapi.Operation{
+			Method:   http.MethodGet,
+			Path:     r.path,
+			Summary:  "List or filter " + r.name + "s",
+			Query:    scimQuery,
+			Response: scim.ListResponse{},
+			Errors:   []*errs.AppError{errs.ScimInvalidFilter},
+		}))
+		d.Add(scimOperation(openapi.Operation{
+			Method:   http.MethodGet,
+			Path:     r.path + "/:id",
+			Summary:  "Read a " + r.name,
+			Response: r.resource,
+			Errors:   []*errs.AppError{errs.ScimResourceNotFound},
+		}))
+		d.Add(scimOperation(openapi.Operation{
+			Method:      http.MethodPost,
+			Path:        r.path,
+			Summary:     "Create a " + r.name,
+			RequestBody: r.resource,
+			Status:      http.StatusCreated,
+			Response:    r.resource,
+			Errors:      []*errs.AppError{errs.ScimInvalidValue},
+		}))
+		d.Add(scimOperation(openapi.Operation{
+			Method:      http.MethodPut,
+			Path:        r.path + "/:id",
+			Summary:     "Replace a " + r.name,
+			RequestBody: r.resource,
+			Response:    r.resource,
+			Errors:      []*errs.AppError{errs.ScimResourceNotFound, errs.ScimInvalidValue},
+		}))
+		d.Add(scimOperation(openapi.Operation{
+			Method:      http.MethodPatch,
+			Path:        r.path + "/:id",
+			Summary:     "Patch a " + r.name,
+			RequestBody: scim.PatchRequest{},
+			Response:    r.resource,
+			Errors:      []*errs.AppError{errs.ScimResourceNotFound, errs.ScimInvalidPatch, errs.ScimInvalidValue},
+		}))
+		d.Add(scimOperation(openapi.Operation{
+			Method:  http.MethodDelete,
+			Path:    r.path + "/:id",
+			Summary: "Delete a " + r.name,
+			Status:  http.StatusNoContent,
+			Errors:  []*errs.AppError{errs.ScimResourceNotFound},
+		}))
+	}
+}
+
+// scimOperation sets what every SCIM route has in common: the SCIM token,
+// SCIM content types and SCIM error bodies.
+func scimOperation(op openapi.Operation) openapi.Operation {
+	op.Tag = "SCIM"
+	op.Security = []string{openapi.SecuritySCIM}
+	op.RequestType = scim.ContentType
+	op.Raw = true
+	op.ResponseType = scim.ContentType
+	op.ErrorResponse = scim.Error{}
+	op.ErrorType = scim.ContentType
+
+	return op
+}
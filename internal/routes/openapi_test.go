@@ -0,0 +1,30 @@
+package routes_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/routes"
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/EputraP/kfc_be/internal/util/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// TestSpecMatchesRoutes fails when a route is registered by Build without
+// being documented by Spec, or the other way around.
+func TestSpecMatchesRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	versions := []apiversion.Version{
+		{Name: apiversion.V1},
+		{Name: apiversion.Unversioned, Deprecation: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC), Successor: apiversion.V1},
+	}
+
+	// The handlers are never called, only the routes are needed
+	srv := gin.New()
+	routes.Build(srv, &routes.Handlers{}, &routes.Middlewares{}, versions)
+
+	if err := openapi.Check(routes.Spec(versions), srv.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,23 +2,98 @@ package routes
 
 import (
 	"github.com/EputraP/kfc_be/internal/handler"
+	"github.com/EputraP/kfc_be/internal/middleware"
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/EputraP/kfc_be/internal/util/openapi"
 	"github.com/gin-gonic/gin"
 )
 
 type Handlers struct {
-	Auth *handler.AuthHandler
+	Auth         *handler.AuthHandler
+	MagicLink    *handler.MagicLinkHandler
+	OTP          *handler.OTPHandler
+	WebAuthn     *handler.WebAuthnHandler
+	OAuth        *handler.OAuthHandler
+	Scim         *handler.ScimHandler
+	Admin        *handler.AdminHandler
+	Organization *handler.OrganizationHandler
+	Health       *handler.HealthHandler
 }
 
 type Middlewares struct {
-	Auth gin.HandlerFunc
+	Auth         gin.HandlerFunc
+	RefreshAuth  gin.HandlerFunc
+	Scim         gin.HandlerFunc
+	Admin        gin.HandlerFunc
+	Organization gin.HandlerFunc
 }
 
-func Build(srv *gin.Engine, h *Handlers, middlewares *Middlewares) {
+// Build registers the routes. The API routes are registered once per version
+// under its prefix; handlers that serve different DTOs per version read it
+// with apiversion.FromContext. Probes, docs and SCIM, which has its own
+// versioning, are not versioned. Metrics are served on their own listener.
+func Build(srv *gin.Engine, h *Handlers, middlewares *Middlewares, versions []apiversion.Version) {
 
-	auth := srv.Group("/auth")
+	srv.GET("/healthz", h.Health.Live)
+	srv.GET("/readyz", h.Health.Ready)
+	srv.GET(OpenAPIPath, openapi.Handler(Spec(versions)))
+	srv.GET(DocsPath, openapi.DocsHandler(apiTitle, OpenAPIPath, DocsAssetsPath))
+	srv.GET(DocsAssetsPath+"/:asset", openapi.DocsAssetHandler())
+
+	for _, version := range versions {
+		buildAPI(srv.Group(version.Prefix(), middleware.APIVersion(version)), h, middlewares)
+	}
+
+	scim := srv.Group("/scim/v2", middlewares.Scim)
+	scim.GET("/Users", h.Scim.ListUsers)
+	scim.GET("/Users/:id", h.Scim.GetUser)
+	scim.POST("/Users", h.Scim.CreateUser)
+	scim.PUT("/Users/:id", h.Scim.ReplaceUser)
+	scim.PATCH("/Users/:id", h.Scim.PatchUser)
+	scim.DELETE("/Users/:id", h.Scim.DeleteUser)
+	scim.GET("/Groups", h.Scim.ListGroups)
+	scim.GET("/Groups/:id", h.Scim.GetGroup)
+	scim.POST("/Groups", h.Scim.CreateGroup)
+	scim.PUT("/Groups/:id", h.Scim.ReplaceGroup)
+	scim.PATCH("/Groups/:id", h.Scim.PatchGroup)
+	scim.DELETE("/Groups/:id", h.Scim.DeleteGroup)
+
+}
+
+// buildAPI registers the versioned routes on api, the group of one version.
+func buildAPI(api *gin.RouterGroup, h *Handlers, middlewares *Middlewares) {
+
+	auth := api.Group("/auth")
 	auth.POST("/register", h.Auth.CreateUser)
 	auth.POST("/login", h.Auth.Login)
-	auth.GET("/refresh", middlewares.Auth, h.Auth.Refresh)
+	auth.POST("/refresh", middlewares.RefreshAuth, h.Auth.Refresh)
 	auth.GET("/logout", h.Auth.Logout)
+	auth.POST("/magic-link", h.MagicLink.Request)
+	auth.GET("/magic-link/callback", h.MagicLink.Callback)
+	auth.POST("/otp/request", h.OTP.Request)
+	auth.POST("/otp/verify", h.OTP.Verify)
+
+	webAuthn := auth.Group("/webauthn")
+	webAuthn.POST("/register/begin", middlewares.Auth, h.WebAuthn.BeginRegistration)
+	webAuthn.POST("/register/finish", middlewares.Auth, h.WebAuthn.FinishRegistration)
+	webAuthn.POST("/login/begin", h.WebAuthn.BeginLogin)
+	webAuthn.POST("/login/finish", h.WebAuthn.FinishLogin)
+
+	oauth := auth.Group("/oauth")
+	oauth.GET("/:provider/start", h.OAuth.Start)
+	oauth.GET("/:provider/callback", h.OAuth.Callback)
+
+	orgs := api.Group("/orgs", middlewares.Auth)
+	orgs.POST("", h.Organization.Create)
+	orgs.GET("", h.Organization.List)
+	orgs.POST("/switch", h.Organization.Switch)
+	orgs.POST("/invitations/accept", h.Organization.AcceptInvitation)
+	orgs.GET("/current/members", middlewares.Organization, h.Organization.ListMembers)
+	orgs.POST("/current/invitations", middlewares.Organization, h.Organization.Invite)
+
+	admin := api.Group("/admin", middlewares.Auth, middlewares.Admin)
+	admin.POST("/users/:id/impersonate", h.Admin.Impersonate)
+	admin.GET("/log-level", h.Admin.GetLogLevel)
+	admin.PUT("/log-level", h.Admin.SetLogLevel)
 
 }
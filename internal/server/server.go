@@ -0,0 +1,192 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/util/logger"
+)
+
+// Readiness reports whether the instance should receive traffic. It starts
+// not-ready and is flipped by Server.Run.
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
+
+func (r *Readiness) set(ready bool) {
+	r.ready.Store(ready)
+}
+
+type Server struct {
+	http            *http.Server
+	readiness       *Readiness
+	drainDelay      time.Duration
+	shutdownTimeout time.Duration
+	workers         []worker
+	closers         []closer
+}
+
+type ServerConfig struct {
+	Addr         string
+	Handler      http.Handler
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+	// DrainDelay is how long requests are still served after Readiness turns
+	// false, before the listener closes.
+	DrainDelay time.Duration
+	// ShutdownTimeout bounds draining requests, stopping workers and running
+	// the closers.
+	ShutdownTimeout time.Duration
+	Readiness       *Readiness
+}
+
+type worker struct {
+	name string
+	run  func(ctx context.Context)
+}
+
+type closer struct {
+	name  string
+	close func(ctx context.Context) error
+}
+
+func New(config ServerConfig) *Server {
+	readiness := config.Readiness
+	if readiness == nil {
+		readiness = &Readiness{}
+	}
+
+	return &Server{
+		http: &http.Server{
+			Addr:         config.Addr,
+			Handler:      config.Handler,
+			ReadTimeout:  config.ReadTimeout,
+			WriteTimeout: config.WriteTimeout,
+			IdleTimeout:  config.IdleTimeout,
+		},
+		readiness:       readiness,
+		drainDelay:      config.DrainDelay,
+		shutdownTimeout: config.ShutdownTimeout,
+	}
+}
+
+// Go registers a background worker. It is started by Run and its context is
+// cancelled once in-flight requests have drained; run must return then.
+func (s *Server) Go(name string, run func(ctx context.Context)) {
+	s.workers = append(s.workers, worker{name: name, run: run})
+}
+
+// OnShutdown registers close to run after the workers stopped. Closers run
+// in registration order, so register the database last.
+func (s *Server) OnShutdown(name string, close func(ctx context.Context) error) {
+	s.closers = append(s.closers, closer{name: name, close: close})
+}
+
+// Run serves until ctx is cancelled, then shuts down: it reports not-ready,
+// waits DrainDelay, drains in-flight requests, stops the workers and runs the
+// closers, all within ShutdownTimeout.
+func (s *Server) Run(ctx context.Context) error {
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	var workers sync.WaitGroup
+	for _, w := range s.workers {
+		workers.Add(1)
+		go func(w worker) {
+			defer workers.Done()
+
+			logger.Info("Server Run", "Starting worker", map[string]string{
+				"worker": w.name,
+			})
+			w.run(workerCtx)
+		}(w)
+	}
+
+	// Readiness is only reported once the port is bound, so a failed bind
+	// never shows up as ready
+	serveErr := make(chan error, 1)
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		serveErr <- err
+	} else {
+		go func() {
+			serveErr <- s.http.Serve(listener)
+		}()
+
+		s.readiness.set(true)
+
+		logger.Info("Server Run", "Server is listening", map[string]string{
+			"addr": listener.Addr().String(),
+		})
+	}
+
+	var runErr error
+	select {
+	case err := <-serveErr:
+		// The listener failed on its own, e.g. the port is taken
+		runErr = err
+	case <-ctx.Done():
+		logger.Info("Server Run", "Shutdown signal received, draining", map[string]string{
+			"drainDelay": s.drainDelay.String(),
+		})
+	}
+
+	s.readiness.set(false)
+
+	if runErr == nil {
+		time.Sleep(s.drainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	var errs []error
+	if runErr != nil && !errors.Is(runErr, http.ErrServerClosed) {
+		errs = append(errs, runErr)
+	}
+
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Server Run", "Error draining requests", map[string]string{
+			"error": err.Error(),
+		})
+		errs = append(errs, err)
+	}
+
+	stopWorkers()
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		logger.Error("Server Run", "Timed out waiting for workers", nil)
+		errs = append(errs, shutdownCtx.Err())
+	}
+
+	for _, c := range s.closers {
+		if err := c.close(shutdownCtx); err != nil {
+			logger.Error("Server Run", "Error closing "+c.name, map[string]string{
+				"error": err.Error(),
+			})
+			errs = append(errs, err)
+		}
+	}
+
+	logger.Info("Server Run", "Server stopped", nil)
+
+	return errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -11,50 +12,72 @@ import (
 	"github.com/EputraP/kfc_be/internal/util/hasher"
 	"github.com/EputraP/kfc_be/internal/util/logger"
 	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/tracing"
 	"gorm.io/gorm"
 )
 
 type AuthService interface {
-	CreateUser(input *dto.RegisterBody) (*dto.RegisterResponse, error)
-	Login(input *dto.LoginBody) (*dto.LoginResponse, error)
+	CreateUser(ctx context.Context, input *dto.RegisterBody) (*dto.RegisterResponse, error)
+	Login(ctx context.Context, input *dto.LoginBody) (*dto.LoginResponse, error)
+	RenewAccessToken(ctx context.Context, refreshToken string) (*string, error)
 }
 type authService struct {
-	authRepo    repository.AuthRepository
-	hasher      hasher.Hasher
-	jtwProvider tokenprovider.JWTTokenProvider
+	txManager        repository.TxManager
+	authRepo         repository.AuthRepository
+	hasher           hasher.Hasher
+	jtwProvider      tokenprovider.JWTTokenProvider
+	organizationRepo repository.OrganizationRepository
+	authenticator    Authenticator
 }
 
 type AuthServiceConfig struct {
+	TxManager   repository.TxManager
 	AuthRepo    repository.AuthRepository
 	Hasher      hasher.Hasher
 	JwtProvider tokenprovider.JWTTokenProvider
+	// OrganizationRepo provides the organization claims of issued tokens.
+	OrganizationRepo repository.OrganizationRepository
+	// Authenticator verifies login credentials. It defaults to the database
+	// authenticator.
+	Authenticator Authenticator
 }
 
 func NewAuthService(config AuthServiceConfig) AuthService {
+	authenticator := config.Authenticator
+	if authenticator == nil {
+		authenticator = NewDatabaseAuthenticator(config.AuthRepo, config.Hasher)
+	}
+
 	return &authService{
-		authRepo:    config.AuthRepo,
-		hasher:      config.Hasher,
-		jtwProvider: config.JwtProvider,
+		txManager:        config.TxManager,
+		authRepo:         config.AuthRepo,
+		hasher:           config.Hasher,
+		jtwProvider:      config.JwtProvider,
+		organizationRepo: config.OrganizationRepo,
+		authenticator:    authenticator,
 	}
 }
 
-func (s *authService) CreateUser(input *dto.RegisterBody) (*dto.RegisterResponse, error) {
-	logger.Info("authService CreateUser", "Executing CreateUser Service", map[string]string{
+func (s *authService) CreateUser(ctx context.Context, input *dto.RegisterBody) (*dto.RegisterResponse, error) {
+	ctx, span := tracing.Start(ctx, "authService CreateUser")
+	defer span.End()
+
+	logger.InfoContext(ctx, "authService CreateUser", "Executing CreateUser Service", map[string]string{
 		"username": input.Username,
 	})
 
 	lowerUsername := strings.ToLower(input.Username)
 
-	userData, err := s.authRepo.SearchUserByUsername(&dto.RegisterBody{Username: lowerUsername})
+	userData, err := s.authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: lowerUsername})
 	if err != nil {
-		logger.Error("authService CreateUser", errs.SearchUsernameError.Error(), map[string]string{
+		logger.ErrorContext(ctx, "authService CreateUser", errs.SearchUsernameError.Error(), map[string]string{
 			"userName": input.Username,
 			"error":    err.Error(),
 		})
 		return nil, errs.SearchUsernameError
 	}
 	if len(userData.Username) != 0 {
-		logger.Error("authService CreateUser", errs.UsernameAlreadyUsed.Error(), map[string]string{
+		logger.ErrorContext(ctx, "authService CreateUser", errs.UsernameAlreadyUsed.Error(), map[string]string{
 			"userName": input.Username,
 		})
 		return nil, errs.UsernameAlreadyUsed
@@ -64,7 +87,7 @@ func (s *authService) CreateUser(input *dto.RegisterBody) (*dto.RegisterResponse
 	isMatch := re.MatchString(input.Password)
 
 	if isMatch {
-		logger.Error("authService CreateUser", errs.PasswordContainUsername.Error(), map[string]string{
+		logger.ErrorContext(ctx, "authService CreateUser", errs.PasswordContainUsername.Error(), map[string]string{
 			"userName": input.Username,
 		})
 		return nil, errs.PasswordContainUsername
@@ -72,17 +95,19 @@ func (s *authService) CreateUser(input *dto.RegisterBody) (*dto.RegisterResponse
 
 	resp := &dto.RegisterResponse{}
 
-	err = repository.AsTransaction(func(tx *gorm.DB) error {
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
 		repoWithTx := s.authRepo.WithTx(tx)
 
+		_, hashSpan := tracing.Start(ctx, "hasher Hash")
 		hashedPassword, _ := s.hasher.Hash(input.Password)
+		hashSpan.End()
 
-		newUser, err := repoWithTx.CreateUser(&dto.RegisterBody{
+		newUser, err := repoWithTx.CreateUser(ctx, &dto.RegisterBody{
 			Username: lowerUsername,
 			Password: hashedPassword,
 		})
 		if err != nil {
-			logger.Error("authService CreateUser", "Error creating new user", map[string]string{
+			logger.ErrorContext(ctx, "authService CreateUser", "Error creating new user", map[string]string{
 				"userName": input.Username,
 				"error":    err.Error(),
 			})
@@ -98,90 +123,82 @@ func (s *authService) CreateUser(input *dto.RegisterBody) (*dto.RegisterResponse
 	})
 
 	if err != nil {
-		logger.Error("authService CreateUser", "Error transaction", map[string]string{
+		logger.ErrorContext(ctx, "authService CreateUser", "Error transaction", map[string]string{
 			"userName": input.Username,
 			"error":    err.Error(),
 		})
 		return nil, err
 	}
 
-	logger.Info("authService CreateUser", "Finished CreateUser Service", map[string]string{
+	logger.InfoContext(ctx, "authService CreateUser", "Finished CreateUser Service", map[string]string{
 		"username": input.Username,
 	})
 
 	return resp, nil
 }
 
-func (s authService) Login(input *dto.LoginBody) (*dto.LoginResponse, error) {
+func (s authService) Login(ctx context.Context, input *dto.LoginBody) (*dto.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "authService Login")
+	defer span.End()
 
-	logger.Info("authService Login", "Executing Login Service", map[string]string{
+	logger.InfoContext(ctx, "authService Login", "Executing Login Service", map[string]string{
 		"username": input.Username,
 	})
 
-	lowerUsername := strings.ToLower(input.Username)
-
-	account, err := s.authRepo.SearchUserByUsername(&dto.RegisterBody{Username: lowerUsername})
-	if err != nil {
-		logger.Error("authService Login", errs.SearchUsernameError.Error(), map[string]string{
-			"userName": input.Username,
-			"error":    err.Error(),
-		})
-		return nil, errs.SearchUsernameError
-	}
-	if len(account.Username) == 0 {
-		logger.Error("authService CreateUser", errs.UsernamePasswordIncorrect.Error(), map[string]string{
-			"userName": input.Username,
-		})
-		return nil, errs.UsernamePasswordIncorrect
-	}
-
-	passwordOk, err := s.hasher.IsEqual(account.Password, input.Password)
+	account, err := s.authenticator.Authenticate(ctx, input)
 	if err != nil {
-		logger.Error("authService CreateUser", errs.CheckPasswordError.Error(), map[string]string{
-			"userName": input.Username,
-			"error":    err.Error(),
-		})
-		return nil, errs.CheckPasswordError
-	}
-
-	if !passwordOk {
-		logger.Error("authService CreateUser", errs.PasswordDoesntMatch.Error(), map[string]string{
-			"userName": input.Username,
-		})
-		return nil, errs.PasswordDoesntMatch
+		tracing.RecordError(span, err)
+		return nil, err
 	}
 
-	loginResponse, err := s.generateLoginResponse(&model.User{Id: account.Id, Username: account.Username})
+	loginResponse, err := s.generateLoginResponse(ctx, account)
 	if err != nil {
-		logger.Error("authService CreateUser", errs.GenerateLoginResponseError.Error(), map[string]string{
+		logger.ErrorContext(ctx, "authService Login", errs.GenerateLoginResponseError.Error(), map[string]string{
 			"userName": input.Username,
 			"error":    err.Error(),
 		})
 		return nil, errs.GenerateLoginResponseError
 	}
 
-	logger.Info("authService Login", "Finished Login Service", map[string]string{
+	logger.InfoContext(ctx, "authService Login", "Finished Login Service", map[string]string{
 		"username": input.Username,
 	})
 
 	return loginResponse, nil
 }
 
-func (as authService) generateLoginResponse(user *model.User) (*dto.LoginResponse, error) {
-	accesToken, err := as.jtwProvider.GenerateAccessToken(*user)
+func (as authService) generateLoginResponse(ctx context.Context, user *model.User) (*dto.LoginResponse, error) {
+	_, span := tracing.Start(ctx, "authService generateLoginResponse")
+	defer span.End()
 
+	return generateLoginResponse(ctx, as.jtwProvider, as.organizationRepo, user)
+}
+
+// RenewAccessToken issues an access token for the user of refreshToken. The
+// organization claims are read again so that a membership removed after the
+// refresh token was issued is not carried over.
+func (s *authService) RenewAccessToken(ctx context.Context, refreshToken string) (*string, error) {
+	ctx, span := tracing.Start(ctx, "authService RenewAccessToken")
+	defer span.End()
+
+	user, err := s.jtwProvider.ParseRefreshToken(refreshToken)
 	if err != nil {
-		return nil, err
+		return nil, errs.InvalidToken.Wrap(err)
 	}
 
-	refreshToken, err := as.jtwProvider.GenerateRefreshToken(*user)
+	claimsUser, err := withOrganizations(ctx, s.organizationRepo, *user)
+	if err != nil {
+		logger.ErrorContext(ctx, "authService RenewAccessToken", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"userId": user.Id.String(),
+			"error":  err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
 
+	accessToken, err := s.jtwProvider.GenerateAccessToken(claimsUser)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.LoginResponse{
-		AccesToken:   accesToken,
-		RefreshToken: refreshToken,
-	}, nil
+	return &accessToken, nil
 }
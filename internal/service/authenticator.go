@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/tracing"
+)
+
+// Authenticator verifies a username and password and returns the matching
+// local user.
+type Authenticator interface {
+	Authenticate(ctx context.Context, input *dto.LoginBody) (*model.User, error)
+}
+
+type databaseAuthenticator struct {
+	authRepo repository.AuthRepository
+	hasher   hasher.Hasher
+}
+
+// NewDatabaseAuthenticator checks the password against the hash stored in
+// the users table.
+func NewDatabaseAuthenticator(authRepo repository.AuthRepository, hasher hasher.Hasher) Authenticator {
+	return &databaseAuthenticator{
+		authRepo: authRepo,
+		hasher:   hasher,
+	}
+}
+
+func (a *databaseAuthenticator) Authenticate(ctx context.Context, input *dto.LoginBody) (*model.User, error) {
+	ctx, span := tracing.Start(ctx, "databaseAuthenticator Authenticate")
+	defer span.End()
+
+	lowerUsername := strings.ToLower(input.Username)
+
+	account, err := a.authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: lowerUsername})
+	if err != nil {
+		logger.ErrorContext(ctx, "databaseAuthenticator Authenticate", errs.SearchUsernameError.Error(), map[string]string{
+			"userName": input.Username,
+			"error":    err.Error(),
+		})
+		return nil, errs.SearchUsernameError
+	}
+	if len(account.Username) == 0 || account.DeletedAt != nil {
+		logger.ErrorContext(ctx, "databaseAuthenticator Authenticate", errs.UsernamePasswordIncorrect.Error(), map[string]string{
+			"userName": input.Username,
+		})
+		return nil, errs.UsernamePasswordIncorrect
+	}
+
+	_, hashSpan := tracing.Start(ctx, "hasher IsEqual")
+	passwordOk, err := a.hasher.IsEqual(account.Password, input.Password)
+	hashSpan.End()
+	if err != nil {
+		logger.ErrorContext(ctx, "databaseAuthenticator Authenticate", errs.CheckPasswordError.Error(), map[string]string{
+			"userName": input.Username,
+			"error":    err.Error(),
+		})
+		return nil, errs.CheckPasswordError
+	}
+
+	if !passwordOk {
+		logger.ErrorContext(ctx, "databaseAuthenticator Authenticate", errs.PasswordDoesntMatch.Error(), map[string]string{
+			"userName": input.Username,
+		})
+		return nil, errs.PasswordDoesntMatch
+	}
+
+	return &model.User{Id: account.Id, Username: account.Username}, nil
+}
+
+type authenticatorSelector struct {
+	defaultAuthenticator Authenticator
+	domainAuthenticators map[string]Authenticator
+}
+
+// NewAuthenticatorSelector routes a login to the authenticator registered for
+// the username domain ("user@domain" or "DOMAIN\user"), falling back to
+// defaultAuthenticator.
+func NewAuthenticatorSelector(defaultAuthenticator Authenticator, domainAuthenticators map[string]Authenticator) Authenticator {
+	normalized := make(map[string]Authenticator, len(domainAuthenticators))
+	for domain, authenticator := range domainAuthenticators {
+		normalized[strings.ToLower(domain)] = authenticator
+	}
+
+	return &authenticatorSelector{
+		defaultAuthenticator: defaultAuthenticator,
+		domainAuthenticators: normalized,
+	}
+}
+
+func (s *authenticatorSelector) Authenticate(ctx context.Context, input *dto.LoginBody) (*model.User, error) {
+	_, domain := splitUsernameDomain(input.Username)
+
+	if authenticator, ok := s.domainAuthenticators[domain]; ok {
+		return authenticator.Authenticate(ctx, input)
+	}
+
+	return s.defaultAuthenticator.Authenticate(ctx, input)
+}
+
+// splitUsernameDomain returns the lower-cased account name and domain of
+// "user@domain" or "DOMAIN\user". The domain is empty for plain usernames.
+func splitUsernameDomain(username string) (string, string) {
+	username = strings.ToLower(username)
+
+	if domain, name, ok := strings.Cut(username, `\`); ok {
+		return name, domain
+	}
+
+	if i := strings.LastIndex(username, "@"); i >= 0 {
+		return username[:i], username[i+1:]
+	}
+
+	return username, ""
+}
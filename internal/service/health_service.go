@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	"github.com/EputraP/kfc_be/internal/util/health"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+)
+
+// healthCheckTimeout bounds each dependency check so a hanging dependency
+// cannot stall the probe.
+const healthCheckTimeout = 2 * time.Second
+
+type HealthService interface {
+	Live(ctx context.Context) *dto.HealthResponse
+	Ready(ctx context.Context) *dto.HealthResponse
+}
+
+type healthService struct {
+	readiness interface{ Ready() bool }
+	checks    map[string]health.Checker
+}
+
+type HealthServiceConfig struct {
+	// Readiness turns false while the server drains, which fails Ready
+	// without running the checks.
+	Readiness interface{ Ready() bool }
+	Checks    map[string]health.Checker
+}
+
+func NewHealthService(config HealthServiceConfig) HealthService {
+	return &healthService{
+		readiness: config.Readiness,
+		checks:    config.Checks,
+	}
+}
+
+func (s *healthService) Live(ctx context.Context) *dto.HealthResponse {
+	return &dto.HealthResponse{Status: dto.HealthStatusUp}
+}
+
+// Ready runs every check concurrently and reports down when the server is
+// draining or any check fails.
+func (s *healthService) Ready(ctx context.Context) *dto.HealthResponse {
+	resp := &dto.HealthResponse{
+		Status: dto.HealthStatusUp,
+		Checks: make(map[string]dto.HealthCheckResult, len(s.checks)),
+	}
+
+	if s.readiness != nil && !s.readiness.Ready() {
+		resp.Status = dto.HealthStatusDown
+		return resp
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for name, checker := range s.checks {
+		wg.Add(1)
+		go func(name string, checker health.Checker) {
+			defer wg.Done()
+
+			result := s.check(ctx, checker)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			resp.Checks[name] = result
+			if result.Status != dto.HealthStatusUp {
+				resp.Status = dto.HealthStatusDown
+
+				logger.WarnContext(ctx, "healthService Ready", "Health check failed", map[string]string{
+					"check": name,
+					"error": result.Error,
+				})
+			}
+		}(name, checker)
+	}
+
+	wg.Wait()
+
+	return resp
+}
+
+func (s *healthService) check(ctx context.Context, checker health.Checker) dto.HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	latency := time.Since(start)
+
+	result := dto.HealthCheckResult{
+		Status:    dto.HealthStatusUp,
+		LatencyMs: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = dto.HealthStatusDown
+		result.Error = err.Error()
+	}
+
+	return result
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/audit"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+)
+
+var impersonationScopes = map[string]bool{
+	constant.ScopeRead:  true,
+	constant.ScopeWrite: true,
+}
+
+type ImpersonationService interface {
+	Impersonate(ctx context.Context, actor tokenprovider.UserClaims, userId uuid.UUID, input *dto.ImpersonateBody) (*dto.ImpersonateResponse, error)
+}
+
+type impersonationService struct {
+	authRepo    repository.AuthRepository
+	roleService RoleService
+	jtwProvider tokenprovider.JWTTokenProvider
+	duration    int
+}
+
+type ImpersonationServiceConfig struct {
+	AuthRepo    repository.AuthRepository
+	RoleService RoleService
+	JwtProvider tokenprovider.JWTTokenProvider
+	// Duration is the lifetime of an impersonation token in minutes.
+	Duration int
+}
+
+func NewImpersonationService(config ImpersonationServiceConfig) ImpersonationService {
+	return &impersonationService{
+		authRepo:    config.AuthRepo,
+		roleService: config.RoleService,
+		jtwProvider: config.JwtProvider,
+		duration:    config.Duration,
+	}
+}
+
+// Impersonate issues a short-lived access token for userId on behalf of the
+// admin in actor. Scopes default to read-only and admins cannot be
+// impersonated.
+func (s *impersonationService) Impersonate(ctx context.Context, actor tokenprovider.UserClaims, userId uuid.UUID, input *dto.ImpersonateBody) (*dto.ImpersonateResponse, error) {
+	logger.InfoContext(ctx, "impersonationService Impersonate", "Executing Impersonate Service", map[string]string{
+		"actorId": actor.UserID,
+		"userId":  userId.String(),
+	})
+
+	scopes := input.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{constant.ScopeRead}
+	}
+	for _, scope := range scopes {
+		if !impersonationScopes[scope] {
+			return nil, errs.InvalidImpersonationScope
+		}
+	}
+
+	actorId, err := uuid.Parse(actor.UserID)
+	if err != nil {
+		return nil, errs.ParseUUIDError
+	}
+	if actorId == userId {
+		return nil, errs.ImpersonationNotAllowed
+	}
+
+	user, err := s.authRepo.SearchUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Id == uuid.Nil {
+		return nil, errs.ImpersonationTargetNotFound
+	}
+
+	isAdmin, err := s.roleService.HasRole(ctx, userId, constant.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+	if isAdmin {
+		logger.ErrorContext(ctx, "impersonationService Impersonate", errs.ImpersonationNotAllowed.Error(), map[string]string{
+			"actorId": actor.UserID,
+			"userId":  userId.String(),
+		})
+		return nil, errs.ImpersonationNotAllowed
+	}
+
+	accessToken, err := s.jtwProvider.GenerateImpersonationToken(
+		*user,
+		model.User{Id: actorId, Username: actor.Username},
+		scopes,
+		time.Duration(s.duration)*time.Minute,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Log(ctx, "impersonation started", map[string]string{
+		"actorId": actor.UserID,
+		"userId":  userId.String(),
+		"reason":  input.Reason,
+		"scope":   strings.Join(scopes, " "),
+	})
+
+	return &dto.ImpersonateResponse{
+		AccesToken: accessToken,
+		ExpiresIn:  s.duration * 60,
+		Scopes:     scopes,
+	}, nil
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/directory"
+	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	roleSourceLDAP = "ldap"
+	// identityProviderLDAP is the user_identities provider linking a local
+	// account to the DN of its directory entry.
+	identityProviderLDAP = "ldap"
+)
+
+type ldapAuthenticator struct {
+	txManager  repository.TxManager
+	authRepo   repository.AuthRepository
+	oauthRepo  repository.OAuthRepository
+	roleRepo   repository.RoleRepository
+	hasher     hasher.Hasher
+	directory  directory.Directory
+	groupRoles map[string]string
+}
+
+type LDAPAuthenticatorConfig struct {
+	TxManager repository.TxManager
+	AuthRepo  repository.AuthRepository
+	// OAuthRepo stores the link between local accounts and directory entries.
+	OAuthRepo repository.OAuthRepository
+	RoleRepo  repository.RoleRepository
+	Hasher    hasher.Hasher
+	Directory directory.Directory
+	// GroupRoles maps directory group DNs to application roles.
+	GroupRoles map[string]string
+}
+
+// NewLDAPAuthenticator binds against the directory and provisions the user
+// into the users table on first login, linked to the DN of the entry. Roles
+// granted through group membership are synchronised on every login. A local
+// account of the same name without that link is never adopted, so directory
+// roles cannot be granted to an account someone else registered.
+func NewLDAPAuthenticator(config LDAPAuthenticatorConfig) Authenticator {
+	groupRoles := make(map[string]string, len(config.GroupRoles))
+	for group, role := range config.GroupRoles {
+		groupRoles[strings.ToLower(group)] = role
+	}
+
+	return &ldapAuthenticator{
+		txManager:  config.TxManager,
+		authRepo:   config.AuthRepo,
+		oauthRepo:  config.OAuthRepo,
+		roleRepo:   config.RoleRepo,
+		hasher:     config.Hasher,
+		directory:  config.Directory,
+		groupRoles: groupRoles,
+	}
+}
+
+func (a *ldapAuthenticator) Authenticate(ctx context.Context, input *dto.LoginBody) (*model.User, error) {
+	name, domain := splitUsernameDomain(input.Username)
+
+	entry, err := a.directory.Authenticate(name, input.Password)
+	if err != nil {
+		if errors.Is(err, directory.ErrInvalidCredentials) {
+			logger.ErrorContext(ctx, "ldapAuthenticator Authenticate", errs.PasswordDoesntMatch.Error(), map[string]string{
+				"userName": input.Username,
+			})
+			return nil, errs.PasswordDoesntMatch
+		}
+		if errors.Is(err, directory.ErrUserNotFound) {
+			logger.ErrorContext(ctx, "ldapAuthenticator Authenticate", errs.UsernamePasswordIncorrect.Error(), map[string]string{
+				"userName": input.Username,
+			})
+			return nil, errs.UsernamePasswordIncorrect
+		}
+		logger.ErrorContext(ctx, "ldapAuthenticator Authenticate", errs.DirectoryUnavailable.Error(), map[string]string{
+			"userName": input.Username,
+			"error":    err.Error(),
+		})
+		return nil, errs.DirectoryUnavailable
+	}
+
+	localUsername := name
+	if domain != "" {
+		localUsername = name + "@" + domain
+	}
+
+	roles := a.mapRoles(ctx, entry.Groups)
+	user := &model.User{}
+
+	err = a.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		authRepoWithTx := a.authRepo.WithTx(tx)
+		oauthRepoWithTx := a.oauthRepo.WithTx(tx)
+
+		account, err := a.linkedAccount(ctx, authRepoWithTx, oauthRepoWithTx, localUsername, entry)
+		if err != nil {
+			return err
+		}
+
+		user = &model.User{Id: account.Id, Username: account.Username}
+
+		return a.roleRepo.WithTx(tx).ReplaceRoles(ctx, account.Id, roleSourceLDAP, roles)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "ldapAuthenticator Authenticate", "Error transaction", map[string]string{
+			"userName": input.Username,
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// linkedAccount returns the account linked to the directory entry,
+// provisioning it when neither the link nor an account of the same name
+// exists.
+func (a *ldapAuthenticator) linkedAccount(ctx context.Context, authRepo repository.AuthRepository, oauthRepo repository.OAuthRepository, username string, entry *directory.Entry) (*model.User, error) {
+	dn := strings.ToLower(entry.DN)
+
+	linked, err := oauthRepo.SearchIdentity(ctx, identityProviderLDAP, dn)
+	if err != nil {
+		return nil, err
+	}
+	if linked.Id != uuid.Nil {
+		if linked.UserDeactivated {
+			return nil, errs.UsernamePasswordIncorrect
+		}
+		return authRepo.SearchUserById(ctx, linked.UserId)
+	}
+
+	account, err := authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: username})
+	if err != nil {
+		return nil, err
+	}
+	if len(account.Username) != 0 {
+		logger.ErrorContext(ctx, "ldapAuthenticator linkedAccount", errs.DirectoryAccountNotLinked.Error(), map[string]string{
+			"userName": username,
+			"dn":       entry.DN,
+		})
+		return nil, errs.DirectoryAccountNotLinked
+	}
+
+	account, err = a.provision(ctx, authRepo, username, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = oauthRepo.CreateIdentity(ctx, &model.UserIdentity{
+		UserId:   account.Id,
+		Provider: identityProviderLDAP,
+		Subject:  dn,
+		Email:    entry.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+// provision creates the local account with an unusable random password so
+// that directory users can never log in through the database authenticator.
+func (a *ldapAuthenticator) provision(ctx context.Context, authRepo repository.AuthRepository, username string, entry *directory.Entry) (*model.User, error) {
+	randomPassword, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := a.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := authRepo.CreateUser(ctx, &dto.RegisterBody{
+		Username: username,
+		Password: hashedPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.Email != "" {
+		_, err = authRepo.CreateUserDetail(ctx, &model.UserDetail{
+			UserId: newUser.Id,
+			Email:  entry.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.InfoContext(ctx, "ldapAuthenticator provision", "Provisioned user from directory", map[string]string{
+		"userName": username,
+		"dn":       entry.DN,
+	})
+
+	return newUser, nil
+}
+
+func (a *ldapAuthenticator) mapRoles(ctx context.Context, groups []string) []string {
+	seen := map[string]bool{}
+	roles := []string{}
+
+	for _, group := range groups {
+		role, ok := a.groupRoles[strings.ToLower(group)]
+		if !ok || seen[role] {
+			continue
+		}
+
+		seen[role] = true
+		roles = append(roles, role)
+	}
+
+	return roles
+}
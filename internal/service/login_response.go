@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+)
+
+// generateLoginResponse is shared by every login flow so that the issued
+// tokens are identical regardless of how the user authenticated.
+func generateLoginResponse(ctx context.Context, jwtProvider tokenprovider.JWTTokenProvider, organizationRepo repository.OrganizationRepository, user *model.User) (*dto.LoginResponse, error) {
+	claimsUser, err := withOrganizations(ctx, organizationRepo, *user)
+	if err != nil {
+		return nil, err
+	}
+
+	accesToken, err := jwtProvider.GenerateAccessToken(claimsUser)
+
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := jwtProvider.GenerateRefreshToken(claimsUser)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.LoginResponse{
+		AccesToken:   accesToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// withOrganizations fills the organization claims of user from the current
+// memberships. The active organization is kept while the user is still a
+// member of it, otherwise the oldest membership becomes active.
+func withOrganizations(ctx context.Context, organizationRepo repository.OrganizationRepository, user model.User) (model.User, error) {
+	organizations, err := organizationRepo.SearchOrganizationsByUserId(ctx, user.Id)
+	if err != nil {
+		return user, err
+	}
+
+	active := user.OrganizationId
+	user.OrganizationId = uuid.Nil
+	user.OrganizationIds = nil
+
+	for _, organization := range organizations {
+		user.OrganizationIds = append(user.OrganizationIds, organization.Id)
+		if organization.Id == active {
+			user.OrganizationId = active
+		}
+	}
+	if user.OrganizationId == uuid.Nil && len(user.OrganizationIds) > 0 {
+		user.OrganizationId = user.OrganizationIds[0]
+	}
+
+	return user, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/mailer"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type MagicLinkService interface {
+	RequestMagicLink(ctx context.Context, input *dto.MagicLinkBody) (string, error)
+	VerifyMagicLink(ctx context.Context, token string, nonce string) (*dto.LoginResponse, error)
+}
+
+type magicLinkService struct {
+	txManager        repository.TxManager
+	authRepo         repository.AuthRepository
+	magicLinkRepo    repository.MagicLinkRepository
+	jtwProvider      tokenprovider.JWTTokenProvider
+	organizationRepo repository.OrganizationRepository
+	mailer           mailer.Mailer
+	secret           string
+	linkURL          string
+	linkDuration     int
+}
+
+type MagicLinkServiceConfig struct {
+	TxManager        repository.TxManager
+	AuthRepo         repository.AuthRepository
+	MagicLinkRepo    repository.MagicLinkRepository
+	JwtProvider      tokenprovider.JWTTokenProvider
+	OrganizationRepo repository.OrganizationRepository
+	Mailer           mailer.Mailer
+	// Secret signs the stored link tokens and device nonces.
+	Secret string
+	// LinkURL is the callback URL the token is appended to.
+	LinkURL string
+	// LinkDuration is the lifetime of a link in minutes.
+	LinkDuration int
+}
+
+func NewMagicLinkService(config MagicLinkServiceConfig) MagicLinkService {
+	return &magicLinkService{
+		txManager:        config.TxManager,
+		authRepo:         config.AuthRepo,
+		magicLinkRepo:    config.MagicLinkRepo,
+		jtwProvider:      config.JwtProvider,
+		organizationRepo: config.OrganizationRepo,
+		mailer:           config.Mailer,
+		secret:           config.Secret,
+		linkURL:          config.LinkURL,
+		linkDuration:     config.LinkDuration,
+	}
+}
+
+// RequestMagicLink emails a login link when the address belongs to a user and
+// returns the device nonce the caller must store in a cookie. The nonce is
+// returned for unknown addresses too and the email is sent in the background,
+// so neither the response nor its timing reveals which emails are registered.
+// A new link replaces the unused links of the user.
+func (s *magicLinkService) RequestMagicLink(ctx context.Context, input *dto.MagicLinkBody) (string, error) {
+	logger.InfoContext(ctx, "magicLinkService RequestMagicLink", "Executing RequestMagicLink Service", map[string]string{
+		"email": input.Email,
+	})
+
+	nonce, err := securetoken.Generate(32)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.authRepo.SearchUserByEmail(ctx, input)
+	if err != nil {
+		logger.ErrorContext(ctx, "magicLinkService RequestMagicLink", errs.SearchEmailError.Error(), map[string]string{
+			"email": input.Email,
+			"error": err.Error(),
+		})
+		return "", errs.SearchEmailError
+	}
+	if user.Id == uuid.Nil || user.DeletedAt != nil {
+		logger.WarnContext(ctx, "magicLinkService RequestMagicLink", "No active user registered with email", map[string]string{
+			"email": input.Email,
+		})
+		return nonce, nil
+	}
+
+	token, err := securetoken.Generate(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		repoWithTx := s.magicLinkRepo.WithTx(tx)
+
+		if err := repoWithTx.InvalidateMagicLinks(ctx, user.Id); err != nil {
+			return err
+		}
+
+		_, err := repoWithTx.CreateMagicLink(ctx, &model.MagicLink{
+			UserId:    user.Id,
+			TokenHash: securetoken.Sign(s.secret, token),
+			NonceHash: securetoken.Sign(s.secret, nonce),
+			ExpiresAt: time.Now().Add(time.Duration(s.linkDuration) * time.Minute),
+		})
+
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "magicLinkService RequestMagicLink", "Error creating magic link", map[string]string{
+			"email": input.Email,
+			"error": err.Error(),
+		})
+		return "", err
+	}
+
+	link, err := s.buildLink(ctx, token)
+	if err != nil {
+		return "", err
+	}
+
+	go s.sendLink(context.WithoutCancel(ctx), input.Email, link)
+
+	logger.InfoContext(ctx, "magicLinkService RequestMagicLink", "Finished RequestMagicLink Service", map[string]string{
+		"email": input.Email,
+	})
+
+	return nonce, nil
+}
+
+// sendLink emails the link. Failures are only logged since the caller has
+// already been answered.
+func (s *magicLinkService) sendLink(ctx context.Context, email string, link string) {
+	body := fmt.Sprintf("Use the link below to sign in. It expires in %d minutes and can only be used once.\n\n%s\n", s.linkDuration, link)
+
+	if err := s.mailer.Send(email, "Your sign-in link", body); err != nil {
+		logger.ErrorContext(ctx, "magicLinkService sendLink", errs.SendMagicLinkError.Error(), map[string]string{
+			"email": email,
+			"error": err.Error(),
+		})
+	}
+}
+
+func (s *magicLinkService) VerifyMagicLink(ctx context.Context, token string, nonce string) (*dto.LoginResponse, error) {
+	logger.InfoContext(ctx, "magicLinkService VerifyMagicLink", "Executing VerifyMagicLink Service", nil)
+
+	if token == "" || nonce == "" {
+		return nil, errs.InvalidMagicLink
+	}
+
+	link := &model.MagicLink{}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		repoWithTx := s.magicLinkRepo.WithTx(tx)
+
+		var err error
+		link, err = repoWithTx.ConsumeMagicLink(ctx, securetoken.Sign(s.secret, token), securetoken.Sign(s.secret, nonce))
+		if err != nil {
+			return err
+		}
+		if link.Id == uuid.Nil {
+			return nil
+		}
+
+		// Links requested before this one must not log in again
+		return repoWithTx.InvalidateMagicLinks(ctx, link.UserId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if link.Id == uuid.Nil {
+		logger.ErrorContext(ctx, "magicLinkService VerifyMagicLink", errs.InvalidMagicLink.Error(), nil)
+		return nil, errs.InvalidMagicLink
+	}
+
+	user, err := s.authRepo.SearchUserById(ctx, link.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Id == uuid.Nil {
+		logger.ErrorContext(ctx, "magicLinkService VerifyMagicLink", "User of magic link no longer exists", map[string]string{
+			"userId": link.UserId.String(),
+		})
+		return nil, errs.InvalidMagicLink
+	}
+
+	loginResponse, err := generateLoginResponse(ctx, s.jtwProvider, s.organizationRepo, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "magicLinkService VerifyMagicLink", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"userId": user.Id.String(),
+			"error":  err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
+
+	logger.InfoContext(ctx, "magicLinkService VerifyMagicLink", "Finished VerifyMagicLink Service", map[string]string{
+		"userId": user.Id.String(),
+	})
+
+	return loginResponse, nil
+}
+
+func (s *magicLinkService) buildLink(ctx context.Context, token string) (string, error) {
+	link, err := url.Parse(s.linkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
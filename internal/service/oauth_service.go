@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/identityprovider"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+type OAuthService interface {
+	Start(ctx context.Context, providerName string) (string, string, error)
+	Callback(ctx context.Context, providerName string, state string, expectedState string, code string) (*dto.LoginResponse, error)
+}
+
+type oauthService struct {
+	txManager        repository.TxManager
+	authRepo         repository.AuthRepository
+	oauthRepo        repository.OAuthRepository
+	hasher           hasher.Hasher
+	jtwProvider      tokenprovider.JWTTokenProvider
+	organizationRepo repository.OrganizationRepository
+	providers        *identityprovider.Registry
+	secret           string
+	stateDuration    int
+}
+
+type OAuthServiceConfig struct {
+	TxManager        repository.TxManager
+	AuthRepo         repository.AuthRepository
+	OAuthRepo        repository.OAuthRepository
+	Hasher           hasher.Hasher
+	JwtProvider      tokenprovider.JWTTokenProvider
+	OrganizationRepo repository.OrganizationRepository
+	Providers        *identityprovider.Registry
+	// Secret signs the stored state values.
+	Secret string
+	// StateDuration is the lifetime of an authorization request in minutes.
+	StateDuration int
+}
+
+func NewOAuthService(config OAuthServiceConfig) OAuthService {
+	return &oauthService{
+		txManager:        config.TxManager,
+		authRepo:         config.AuthRepo,
+		oauthRepo:        config.OAuthRepo,
+		hasher:           config.Hasher,
+		jtwProvider:      config.JwtProvider,
+		organizationRepo: config.OrganizationRepo,
+		providers:        config.Providers,
+		secret:           config.Secret,
+		stateDuration:    config.StateDuration,
+	}
+}
+
+// Start creates the state, nonce and PKCE verifier of a new authorization
+// request and returns the provider URL together with the state the caller
+// must bind to the browser.
+func (s *oauthService) Start(ctx context.Context, providerName string) (string, string, error) {
+	logger.InfoContext(ctx, "oauthService Start", "Executing Start Service", map[string]string{
+		"provider": providerName,
+	})
+
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return "", "", errs.OAuthProviderNotFound
+	}
+
+	state, err := securetoken.Generate(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	nonce, err := securetoken.Generate(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	codeVerifier := oauth2.GenerateVerifier()
+
+	_, err = s.oauthRepo.CreateState(ctx, &model.OAuthState{
+		Provider:     providerName,
+		StateHash:    securetoken.Sign(s.secret, state),
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    time.Now().Add(time.Duration(s.stateDuration) * time.Minute),
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "oauthService Start", "Error storing state", map[string]string{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		return "", "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		logger.ErrorContext(ctx, "oauthService Start", errs.OAuthProviderUnavailable.Error(), map[string]string{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		return "", "", errs.OAuthProviderUnavailable
+	}
+
+	logger.InfoContext(ctx, "oauthService Start", "Finished Start Service", map[string]string{
+		"provider": providerName,
+	})
+
+	return authURL, state, nil
+}
+
+// Callback exchanges the authorization code and logs in the linked user. An
+// identity seen for the first time is linked to the user owning the same
+// verified email, or to a newly registered user.
+func (s *oauthService) Callback(ctx context.Context, providerName string, state string, expectedState string, code string) (*dto.LoginResponse, error) {
+	logger.InfoContext(ctx, "oauthService Callback", "Executing Callback Service", map[string]string{
+		"provider": providerName,
+	})
+
+	provider, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, errs.OAuthProviderNotFound
+	}
+
+	if state == "" || !securetoken.Equal(state, expectedState) {
+		logger.ErrorContext(ctx, "oauthService Callback", errs.InvalidOAuthState.Error(), map[string]string{
+			"provider": providerName,
+		})
+		return nil, errs.InvalidOAuthState
+	}
+
+	storedState, err := s.oauthRepo.ConsumeState(ctx, providerName, securetoken.Sign(s.secret, state))
+	if err != nil {
+		return nil, err
+	}
+	if storedState.Id == uuid.Nil {
+		logger.ErrorContext(ctx, "oauthService Callback", errs.InvalidOAuthState.Error(), map[string]string{
+			"provider": providerName,
+		})
+		return nil, errs.InvalidOAuthState
+	}
+
+	identity, err := provider.Exchange(ctx, code, storedState.CodeVerifier, storedState.Nonce)
+	if err != nil {
+		logger.ErrorContext(ctx, "oauthService Callback", errs.OAuthExchangeFailed.Error(), map[string]string{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		return nil, errs.OAuthExchangeFailed
+	}
+
+	user := &model.User{}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		user, err = s.resolveUser(ctx, s.authRepo.WithTx(tx), s.oauthRepo.WithTx(tx), providerName, identity)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "oauthService Callback", "Error transaction", map[string]string{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	loginResponse, err := generateLoginResponse(ctx, s.jtwProvider, s.organizationRepo, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "oauthService Callback", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"provider": providerName,
+			"error":    err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
+
+	logger.InfoContext(ctx, "oauthService Callback", "Finished Callback Service", map[string]string{
+		"provider": providerName,
+		"userId":   user.Id.String(),
+	})
+
+	return loginResponse, nil
+}
+
+func (s *oauthService) resolveUser(ctx context.Context, authRepo repository.AuthRepository, oauthRepo repository.OAuthRepository, providerName string, identity *identityprovider.Identity) (*model.User, error) {
+	linked, err := oauthRepo.SearchIdentity(ctx, providerName, identity.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if linked.Id != uuid.Nil {
+		// The identity stays linked, it must not be registered again
+		if linked.UserDeactivated {
+			return nil, errs.AccountDeactivated
+		}
+		return authRepo.SearchUserById(ctx, linked.UserId)
+	}
+
+	user := &model.User{}
+
+	if identity.Email != "" && identity.EmailVerified {
+		user, err = authRepo.SearchUserByEmail(ctx, &dto.MagicLinkBody{Email: identity.Email})
+		if err != nil {
+			return nil, err
+		}
+		if user.DeletedAt != nil {
+			return nil, errs.AccountDeactivated
+		}
+	}
+
+	if user.Id == uuid.Nil {
+		user, err = s.registerIdentity(ctx, authRepo, providerName, identity)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = oauthRepo.CreateIdentity(ctx, &model.UserIdentity{
+		UserId:   user.Id,
+		Provider: providerName,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// registerIdentity creates a user with an unusable random password. The
+// verified email is used as username when it is still free.
+func (s *oauthService) registerIdentity(ctx context.Context, authRepo repository.AuthRepository, providerName string, identity *identityprovider.Identity) (*model.User, error) {
+	username := strings.ToLower(fmt.Sprintf("%s_%s", providerName, identity.Subject))
+
+	if identity.Email != "" && identity.EmailVerified {
+		existing, err := authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: strings.ToLower(identity.Email)})
+		if err != nil {
+			return nil, err
+		}
+		if len(existing.Username) == 0 {
+			username = strings.ToLower(identity.Email)
+		}
+	}
+
+	randomPassword, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := authRepo.CreateUser(ctx, &dto.RegisterBody{
+		Username: username,
+		Password: hashedPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if identity.Email != "" && identity.EmailVerified {
+		_, err = authRepo.CreateUserDetail(ctx, &model.UserDetail{
+			UserId: newUser.Id,
+			Email:  identity.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.InfoContext(ctx, "oauthService registerIdentity", "Registered user from external identity", map[string]string{
+		"provider": providerName,
+		"userId":   newUser.Id.String(),
+	})
+
+	return newUser, nil
+}
@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/mailer"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var organizationRoles = map[string]bool{
+	constant.OrganizationRoleOwner:  true,
+	constant.OrganizationRoleAdmin:  true,
+	constant.OrganizationRoleMember: true,
+}
+
+type OrganizationService interface {
+	CreateOrganization(ctx context.Context, userId uuid.UUID, input *dto.CreateOrganizationBody) (*model.Organization, error)
+	ListOrganizations(ctx context.Context, userId uuid.UUID) ([]model.Organization, error)
+	SwitchOrganization(ctx context.Context, claims tokenprovider.UserClaims, organizationId uuid.UUID) (*dto.LoginResponse, error)
+	SearchMembership(ctx context.Context, organizationId uuid.UUID, userId uuid.UUID) (*model.Membership, error)
+	ListMembers(ctx context.Context, organizationId uuid.UUID) ([]model.Membership, error)
+	InviteMember(ctx context.Context, inviter *model.Membership, input *dto.InviteMemberBody) (*model.OrganizationInvitation, error)
+	AcceptInvitation(ctx context.Context, userId uuid.UUID, input *dto.AcceptInvitationBody) (*model.Membership, error)
+}
+
+type organizationService struct {
+	txManager          repository.TxManager
+	authRepo           repository.AuthRepository
+	organizationRepo   repository.OrganizationRepository
+	membershipRepo     repository.MembershipRepository
+	jtwProvider        tokenprovider.JWTTokenProvider
+	mailer             mailer.Mailer
+	secret             string
+	invitationURL      string
+	invitationDuration int
+}
+
+type OrganizationServiceConfig struct {
+	TxManager        repository.TxManager
+	AuthRepo         repository.AuthRepository
+	OrganizationRepo repository.OrganizationRepository
+	MembershipRepo   repository.MembershipRepository
+	JwtProvider      tokenprovider.JWTTokenProvider
+	Mailer           mailer.Mailer
+	// Secret signs the stored invitation tokens.
+	Secret string
+	// InvitationURL is the frontend URL the invitation token is appended to.
+	InvitationURL string
+	// InvitationDuration is the lifetime of an invitation in hours.
+	InvitationDuration int
+}
+
+func NewOrganizationService(config OrganizationServiceConfig) OrganizationService {
+	return &organizationService{
+		txManager:          config.TxManager,
+		authRepo:           config.AuthRepo,
+		organizationRepo:   config.OrganizationRepo,
+		membershipRepo:     config.MembershipRepo,
+		jtwProvider:        config.JwtProvider,
+		mailer:             config.Mailer,
+		secret:             config.Secret,
+		invitationURL:      config.InvitationURL,
+		invitationDuration: config.InvitationDuration,
+	}
+}
+
+// CreateOrganization creates the organization with userId as its owner.
+func (s *organizationService) CreateOrganization(ctx context.Context, userId uuid.UUID, input *dto.CreateOrganizationBody) (*model.Organization, error) {
+	logger.InfoContext(ctx, "organizationService CreateOrganization", "Executing CreateOrganization Service", map[string]string{
+		"userId": userId.String(),
+		"name":   input.Name,
+	})
+
+	organization := &model.Organization{}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		var err error
+
+		organization, err = s.organizationRepo.WithTx(tx).CreateOrganization(ctx, input.Name, userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.membershipRepo.WithTx(tx).ForOrganization(organization.Id).CreateMembership(ctx, userId, constant.OrganizationRoleOwner)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "organizationService CreateOrganization", "Error transaction", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	organization.Role = constant.OrganizationRoleOwner
+
+	return organization, nil
+}
+
+func (s *organizationService) ListOrganizations(ctx context.Context, userId uuid.UUID) ([]model.Organization, error) {
+	return s.organizationRepo.SearchOrganizationsByUserId(ctx, userId)
+}
+
+// SwitchOrganization re-issues the token pair with organizationId as the
+// active organization. Impersonation tokens cannot switch because that would
+// hand out a refresh token.
+func (s *organizationService) SwitchOrganization(ctx context.Context, claims tokenprovider.UserClaims, organizationId uuid.UUID) (*dto.LoginResponse, error) {
+	logger.InfoContext(ctx, "organizationService SwitchOrganization", "Executing SwitchOrganization Service", map[string]string{
+		"userId":         claims.UserID,
+		"organizationId": organizationId.String(),
+	})
+
+	if claims.IsImpersonated() {
+		return nil, errs.ForbiddenAccess
+	}
+
+	userId, err := uuid.Parse(claims.UserID)
+	if err != nil {
+		return nil, errs.ParseUUIDError
+	}
+
+	if _, err := s.SearchMembership(ctx, organizationId, userId); err != nil {
+		return nil, err
+	}
+
+	user := &model.User{
+		Id:             userId,
+		Username:       claims.Username,
+		OrganizationId: organizationId,
+	}
+
+	loginResponse, err := generateLoginResponse(ctx, s.jtwProvider, s.organizationRepo, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "organizationService SwitchOrganization", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"userId": claims.UserID,
+			"error":  err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
+
+	return loginResponse, nil
+}
+
+func (s *organizationService) SearchMembership(ctx context.Context, organizationId uuid.UUID, userId uuid.UUID) (*model.Membership, error) {
+	membership, err := s.membershipRepo.ForOrganization(organizationId).SearchMembership(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if membership.Id == uuid.Nil {
+		return nil, errs.OrganizationNotMember
+	}
+
+	return membership, nil
+}
+
+func (s *organizationService) ListMembers(ctx context.Context, organizationId uuid.UUID) ([]model.Membership, error) {
+	return s.membershipRepo.ForOrganization(organizationId).ListMemberships(ctx)
+}
+
+// InviteMember emails a single-use invitation link. Only owners and admins of
+// the organization can invite, and only owners can invite other owners.
+func (s *organizationService) InviteMember(ctx context.Context, inviter *model.Membership, input *dto.InviteMemberBody) (*model.OrganizationInvitation, error) {
+	logger.InfoContext(ctx, "organizationService InviteMember", "Executing InviteMember Service", map[string]string{
+		"organizationId": inviter.OrganizationId.String(),
+		"email":          input.Email,
+	})
+
+	role := input.Role
+	if role == "" {
+		role = constant.OrganizationRoleMember
+	}
+	if !organizationRoles[role] {
+		return nil, errs.InvalidOrganizationRole
+	}
+
+	if inviter.Role != constant.OrganizationRoleOwner &&
+		(inviter.Role != constant.OrganizationRoleAdmin || role == constant.OrganizationRoleOwner) {
+		return nil, errs.ForbiddenAccess
+	}
+
+	token, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation, err := s.membershipRepo.ForOrganization(inviter.OrganizationId).CreateInvitation(ctx, &model.OrganizationInvitation{
+		Email:     strings.ToLower(input.Email),
+		Role:      role,
+		TokenHash: securetoken.Sign(s.secret, token),
+		InvitedBy: inviter.UserId,
+		ExpiresAt: time.Now().Add(time.Duration(s.invitationDuration) * time.Hour),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	link, err := url.Parse(s.invitationURL)
+	if err != nil {
+		return nil, err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	body := fmt.Sprintf("%s invited you to join their organization. The invitation expires in %d hours.\n\n%s\n", inviter.Username, s.invitationDuration, link.String())
+
+	if err := s.mailer.Send(input.Email, "You have been invited", body); err != nil {
+		logger.ErrorContext(ctx, "organizationService InviteMember", errs.SendInvitationError.Error(), map[string]string{
+			"email": input.Email,
+			"error": err.Error(),
+		})
+		return nil, errs.SendInvitationError
+	}
+
+	return invitation, nil
+}
+
+// AcceptInvitation adds userId to the inviting organization. The invitation
+// must have been sent to the email registered for userId.
+func (s *organizationService) AcceptInvitation(ctx context.Context, userId uuid.UUID, input *dto.AcceptInvitationBody) (*model.Membership, error) {
+	logger.InfoContext(ctx, "organizationService AcceptInvitation", "Executing AcceptInvitation Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	membership := &model.Membership{}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		invitation, err := s.organizationRepo.WithTx(tx).ConsumeInvitation(ctx, securetoken.Sign(s.secret, input.Token))
+		if err != nil {
+			return err
+		}
+		if invitation.Id == uuid.Nil {
+			return errs.InvalidInvitation
+		}
+
+		invitee, err := s.authRepo.WithTx(tx).SearchUserByEmail(ctx, &dto.MagicLinkBody{Email: invitation.Email})
+		if err != nil {
+			return err
+		}
+		if invitee.Id != userId || invitee.DeletedAt != nil {
+			return errs.InvalidInvitation
+		}
+
+		membership, err = s.membershipRepo.WithTx(tx).ForOrganization(invitation.OrganizationId).CreateMembership(ctx, userId, invitation.Role)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "organizationService AcceptInvitation", "Error transaction", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return membership, nil
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/EputraP/kfc_be/internal/util/sms"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const otpDigits = 6
+
+type OTPService interface {
+	RequestOTP(ctx context.Context, input *dto.OTPRequestBody) error
+	VerifyOTP(ctx context.Context, input *dto.OTPVerifyBody) (*dto.LoginResponse, error)
+}
+
+type otpService struct {
+	txManager        repository.TxManager
+	authRepo         repository.AuthRepository
+	otpRepo          repository.OTPRepository
+	hasher           hasher.Hasher
+	jtwProvider      tokenprovider.JWTTokenProvider
+	organizationRepo repository.OrganizationRepository
+	smsSender        sms.SMSSender
+	secret           string
+	duration         int
+	maxAttempts      int
+	requestLimit     int
+	requestWindow    time.Duration
+}
+
+type OTPServiceConfig struct {
+	TxManager        repository.TxManager
+	AuthRepo         repository.AuthRepository
+	OTPRepo          repository.OTPRepository
+	Hasher           hasher.Hasher
+	JwtProvider      tokenprovider.JWTTokenProvider
+	OrganizationRepo repository.OrganizationRepository
+	SMSSender        sms.SMSSender
+	// Secret signs the stored codes.
+	Secret string
+	// Duration is the lifetime of a code in minutes.
+	Duration    int
+	MaxAttempts int
+	// At most RequestLimit codes are sent to a phone number per
+	// RequestWindow, which also bounds the guesses a new code allows.
+	RequestLimit  int
+	RequestWindow time.Duration
+}
+
+func NewOTPService(config OTPServiceConfig) OTPService {
+	return &otpService{
+		txManager:        config.TxManager,
+		authRepo:         config.AuthRepo,
+		otpRepo:          config.OTPRepo,
+		hasher:           config.Hasher,
+		jtwProvider:      config.JwtProvider,
+		organizationRepo: config.OrganizationRepo,
+		smsSender:        config.SMSSender,
+		secret:           config.Secret,
+		duration:         config.Duration,
+		maxAttempts:      config.MaxAttempts,
+		requestLimit:     config.RequestLimit,
+		requestWindow:    config.RequestWindow,
+	}
+}
+
+func (s *otpService) RequestOTP(ctx context.Context, input *dto.OTPRequestBody) error {
+	logger.InfoContext(ctx, "otpService RequestOTP", "Executing RequestOTP Service", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	code, err := securetoken.GenerateNumeric(otpDigits)
+	if err != nil {
+		return err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		repoWithTx := s.otpRepo.WithTx(tx)
+
+		// Parallel requests would otherwise all pass the count below
+		if err := repoWithTx.LockPhoneNumber(ctx, input.PhoneNumber); err != nil {
+			return err
+		}
+
+		sent, err := repoWithTx.CountOTPsSince(ctx, input.PhoneNumber, time.Now().Add(-s.requestWindow))
+		if err != nil {
+			return err
+		}
+		if sent >= int64(s.requestLimit) {
+			return errs.OTPRequestsExceeded
+		}
+
+		if err := repoWithTx.ConsumeOTPs(ctx, input.PhoneNumber); err != nil {
+			return err
+		}
+
+		_, err = repoWithTx.CreateOTP(ctx, &model.OTP{
+			PhoneNumber: input.PhoneNumber,
+			CodeHash:    s.signCode(input.PhoneNumber, code),
+			ExpiresAt:   time.Now().Add(time.Duration(s.duration) * time.Minute),
+		})
+
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "otpService RequestOTP", "Error creating OTP", map[string]string{
+			"phoneNumber": input.PhoneNumber,
+			"error":       err.Error(),
+		})
+		return err
+	}
+
+	message := fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, s.duration)
+
+	if err := s.smsSender.Send(input.PhoneNumber, message); err != nil {
+		logger.ErrorContext(ctx, "otpService RequestOTP", errs.SendOTPError.Error(), map[string]string{
+			"phoneNumber": input.PhoneNumber,
+			"error":       err.Error(),
+		})
+		return errs.SendOTPError
+	}
+
+	logger.InfoContext(ctx, "otpService RequestOTP", "Finished RequestOTP Service", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	return nil
+}
+
+// VerifyOTP checks the code and logs the owner of the phone number in. A new
+// account is registered when the number is not linked to any user yet.
+func (s *otpService) VerifyOTP(ctx context.Context, input *dto.OTPVerifyBody) (*dto.LoginResponse, error) {
+	logger.InfoContext(ctx, "otpService VerifyOTP", "Executing VerifyOTP Service", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	otp, err := s.otpRepo.SearchActiveOTP(ctx, input.PhoneNumber)
+	if err != nil {
+		return nil, err
+	}
+	if otp.Id == uuid.Nil {
+		logger.ErrorContext(ctx, "otpService VerifyOTP", errs.InvalidOTP.Error(), map[string]string{
+			"phoneNumber": input.PhoneNumber,
+		})
+		return nil, errs.InvalidOTP
+	}
+
+	claimed, err := s.otpRepo.ClaimOTPAttempt(ctx, otp.Id, s.maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		logger.ErrorContext(ctx, "otpService VerifyOTP", errs.OTPAttemptsExceeded.Error(), map[string]string{
+			"phoneNumber": input.PhoneNumber,
+		})
+		return nil, errs.OTPAttemptsExceeded
+	}
+
+	if !securetoken.Equal(otp.CodeHash, s.signCode(input.PhoneNumber, input.Code)) {
+		logger.ErrorContext(ctx, "otpService VerifyOTP", errs.InvalidOTP.Error(), map[string]string{
+			"phoneNumber": input.PhoneNumber,
+		})
+		return nil, errs.InvalidOTP
+	}
+
+	user := &model.User{}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		otpRepoWithTx := s.otpRepo.WithTx(tx)
+		authRepoWithTx := s.authRepo.WithTx(tx)
+
+		// Of parallel verifications of the same code only one consumes it
+		consumed, err := otpRepoWithTx.ConsumeOTP(ctx, otp.Id)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			return errs.InvalidOTP
+		}
+
+		if err := otpRepoWithTx.ConsumeOTPs(ctx, input.PhoneNumber); err != nil {
+			return err
+		}
+
+		account, err := authRepoWithTx.SearchUserByPhoneNumber(ctx, input.PhoneNumber)
+		if err != nil {
+			return err
+		}
+		if account.DeletedAt != nil {
+			return errs.AccountDeactivated
+		}
+		if account.Id != uuid.Nil {
+			user = account
+			return nil
+		}
+
+		user, err = s.registerPhoneNumber(ctx, authRepoWithTx, input.PhoneNumber)
+		return err
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "otpService VerifyOTP", "Error transaction", map[string]string{
+			"phoneNumber": input.PhoneNumber,
+			"error":       err.Error(),
+		})
+		return nil, err
+	}
+
+	loginResponse, err := generateLoginResponse(ctx, s.jtwProvider, s.organizationRepo, user)
+	if err != nil {
+		logger.ErrorContext(ctx, "otpService VerifyOTP", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"phoneNumber": input.PhoneNumber,
+			"error":       err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
+
+	logger.InfoContext(ctx, "otpService VerifyOTP", "Finished VerifyOTP Service", map[string]string{
+		"phoneNumber": input.PhoneNumber,
+	})
+
+	return loginResponse, nil
+}
+
+// registerPhoneNumber creates a user named after the phone number with an
+// unusable random password, so the account can only log in through OTP until
+// a password is set.
+func (s *otpService) registerPhoneNumber(ctx context.Context, authRepo repository.AuthRepository, phoneNumber string) (*model.User, error) {
+	randomPassword, err := securetoken.Generate(32)
+	if err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.hasher.Hash(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := authRepo.CreateUser(ctx, &dto.RegisterBody{
+		Username: phoneNumber,
+		Password: hashedPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = authRepo.CreateUserDetail(ctx, &model.UserDetail{
+		UserId:      newUser.Id,
+		PhoneNumber: phoneNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "otpService registerPhoneNumber", "Registered user from phone number", map[string]string{
+		"phoneNumber": phoneNumber,
+		"userId":      newUser.Id.String(),
+	})
+
+	return newUser, nil
+}
+
+func (s *otpService) signCode(phoneNumber string, code string) string {
+	return securetoken.Sign(s.secret, phoneNumber+":"+code)
+}
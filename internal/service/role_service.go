@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/google/uuid"
+)
+
+type RoleService interface {
+	HasRole(ctx context.Context, userId uuid.UUID, role string) (bool, error)
+}
+
+type roleService struct {
+	roleRepo repository.RoleRepository
+}
+
+type RoleServiceConfig struct {
+	RoleRepo repository.RoleRepository
+}
+
+func NewRoleService(config RoleServiceConfig) RoleService {
+	return &roleService{
+		roleRepo: config.RoleRepo,
+	}
+}
+
+func (s *roleService) HasRole(ctx context.Context, userId uuid.UUID, role string) (bool, error) {
+	roles, err := s.roleRepo.SearchRolesByUserId(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+
+	for _, r := range roles {
+		if r == role {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
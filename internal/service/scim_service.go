@@ -0,0 +1,982 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/audit"
+	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/scim"
+	"github.com/EputraP/kfc_be/internal/util/securetoken"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	roleSourceSCIM = "scim"
+
+	scimDefaultCount = 100
+	scimMaxCount     = 200
+)
+
+// scimUserColumns whitelists the filterable SCIM user attributes.
+var scimUserColumns = map[string]string{
+	"username":     "u.username",
+	"externalid":   "u.external_id",
+	"emails":       "ud.email",
+	"phonenumbers": "ud.phone_number",
+	"id":           "u.id::text",
+}
+
+type ScimService interface {
+	ListUsers(ctx context.Context, filter string, startIndex int, count int) (*scim.ListResponse, error)
+	GetUser(ctx context.Context, userId uuid.UUID) (*scim.User, error)
+	CreateUser(ctx context.Context, input *scim.User) (*scim.User, error)
+	ReplaceUser(ctx context.Context, userId uuid.UUID, input *scim.User) (*scim.User, error)
+	PatchUser(ctx context.Context, userId uuid.UUID, input *scim.PatchRequest) (*scim.User, error)
+	DeleteUser(ctx context.Context, userId uuid.UUID) error
+
+	ListGroups(ctx context.Context, filter string, startIndex int, count int) (*scim.ListResponse, error)
+	GetGroup(ctx context.Context, roleId uuid.UUID) (*scim.Group, error)
+	CreateGroup(ctx context.Context, input *scim.Group) (*scim.Group, error)
+	ReplaceGroup(ctx context.Context, roleId uuid.UUID, input *scim.Group) (*scim.Group, error)
+	PatchGroup(ctx context.Context, roleId uuid.UUID, input *scim.PatchRequest) (*scim.Group, error)
+	DeleteGroup(ctx context.Context, roleId uuid.UUID) error
+}
+
+type scimService struct {
+	txManager   repository.TxManager
+	authRepo    repository.AuthRepository
+	userRepo    repository.UserRepository
+	roleRepo    repository.RoleRepository
+	sessionRepo repository.SessionRepository
+	hasher      hasher.Hasher
+}
+
+type ScimServiceConfig struct {
+	TxManager   repository.TxManager
+	AuthRepo    repository.AuthRepository
+	UserRepo    repository.UserRepository
+	RoleRepo    repository.RoleRepository
+	SessionRepo repository.SessionRepository
+	Hasher      hasher.Hasher
+}
+
+func NewScimService(config ScimServiceConfig) ScimService {
+	return &scimService{
+		txManager:   config.TxManager,
+		authRepo:    config.AuthRepo,
+		userRepo:    config.UserRepo,
+		roleRepo:    config.RoleRepo,
+		sessionRepo: config.SessionRepo,
+		hasher:      config.Hasher,
+	}
+}
+
+func (s *scimService) ListUsers(ctx context.Context, filter string, startIndex int, count int) (*scim.ListResponse, error) {
+	logger.InfoContext(ctx, "scimService ListUsers", "Executing ListUsers Service", map[string]string{
+		"filter": filter,
+	})
+
+	userFilter, err := toUserFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	startIndex, count = scimPage(startIndex, count)
+
+	accounts, total, err := s.userRepo.ListUsers(ctx, userFilter, startIndex-1, count)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*scim.User, 0, len(accounts))
+	for i := range accounts {
+		user, err := s.toScimUser(ctx, &accounts[i])
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, user)
+	}
+
+	return &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (s *scimService) GetUser(ctx context.Context, userId uuid.UUID) (*scim.User, error) {
+	account, err := s.searchAccount(ctx, s.userRepo, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toScimUser(ctx, account)
+}
+
+func (s *scimService) CreateUser(ctx context.Context, input *scim.User) (*scim.User, error) {
+	logger.InfoContext(ctx, "scimService CreateUser", "Executing CreateUser Service", map[string]string{
+		"userName": input.UserName,
+	})
+
+	account := fromScimUser(input)
+
+	hashedPassword, err := s.hashPassword(ctx, input.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		userRepoWithTx := s.userRepo.WithTx(tx)
+
+		if err := s.checkUsernameFree(ctx, s.authRepo.WithTx(tx), account.Username, uuid.Nil); err != nil {
+			return err
+		}
+
+		created, err := userRepoWithTx.CreateUserAccount(ctx, account, hashedPassword)
+		if err != nil {
+			return err
+		}
+		account.Id = created.Id
+
+		if input.Active != nil && !*input.Active {
+			return userRepoWithTx.SetUserActive(ctx, created.Id, false)
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService CreateUser", "Error transaction", map[string]string{
+			"userName": input.UserName,
+			"error":    err.Error(),
+		})
+		return nil, err
+	}
+
+	logger.InfoContext(ctx, "scimService CreateUser", "Finished CreateUser Service", map[string]string{
+		"userName": input.UserName,
+		"userId":   account.Id.String(),
+	})
+
+	return s.GetUser(ctx, account.Id)
+}
+
+func (s *scimService) ReplaceUser(ctx context.Context, userId uuid.UUID, input *scim.User) (*scim.User, error) {
+	logger.InfoContext(ctx, "scimService ReplaceUser", "Executing ReplaceUser Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		userRepoWithTx := s.userRepo.WithTx(tx)
+
+		current, err := s.searchAccount(ctx, userRepoWithTx, userId)
+		if err != nil {
+			return err
+		}
+
+		account := fromScimUser(input)
+		account.Id = userId
+
+		if err := s.checkUsernameFree(ctx, s.authRepo.WithTx(tx), account.Username, userId); err != nil {
+			return err
+		}
+
+		if err := userRepoWithTx.UpdateUserAccount(ctx, account); err != nil {
+			return err
+		}
+
+		if input.Password != "" {
+			if err := s.updatePassword(ctx, tx, userId, input.Password); err != nil {
+				return err
+			}
+		}
+
+		active := true
+		if input.Active != nil {
+			active = *input.Active
+		}
+
+		return s.setActive(ctx, tx, current, active)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService ReplaceUser", "Error transaction", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return s.GetUser(ctx, userId)
+}
+
+func (s *scimService) PatchUser(ctx context.Context, userId uuid.UUID, input *scim.PatchRequest) (*scim.User, error) {
+	logger.InfoContext(ctx, "scimService PatchUser", "Executing PatchUser Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		userRepoWithTx := s.userRepo.WithTx(tx)
+
+		current, err := s.searchAccount(ctx, userRepoWithTx, userId)
+		if err != nil {
+			return err
+		}
+
+		account := *current
+		active := current.DeletedAt == nil
+		password := ""
+
+		for _, operation := range input.Operations {
+			op := strings.ToLower(operation.Op)
+			if op != "add" && op != "replace" && op != "remove" {
+				return errs.ScimInvalidPatch
+			}
+
+			attributes, err := patchAttributes(operation)
+			if err != nil {
+				return err
+			}
+
+			for path, value := range attributes {
+				if op == "remove" {
+					value = nil
+				}
+
+				switch scim.AttributeName(path) {
+				case "active":
+					active, err = scimBool(value)
+				case "username":
+					account.Username, err = scimString(value)
+					account.Username = strings.ToLower(account.Username)
+				case "externalid":
+					account.ExternalId, err = scimString(value)
+				case "emails":
+					account.Email, err = scimMultiValue(value)
+				case "phonenumbers":
+					account.PhoneNumber, err = scimMultiValue(value)
+				case "addresses":
+					account.Address, err = scimMultiValue(value)
+				case "password":
+					password, err = scimString(value)
+				default:
+					logger.WarnContext(ctx, "scimService PatchUser", "Ignoring unsupported attribute", map[string]string{
+						"path": path,
+					})
+				}
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if account.Username == "" {
+			return errs.ScimInvalidValue
+		}
+
+		if err := s.checkUsernameFree(ctx, s.authRepo.WithTx(tx), account.Username, userId); err != nil {
+			return err
+		}
+
+		if err := userRepoWithTx.UpdateUserAccount(ctx, &account); err != nil {
+			return err
+		}
+
+		if password != "" {
+			if err := s.updatePassword(ctx, tx, userId, password); err != nil {
+				return err
+			}
+		}
+
+		return s.setActive(ctx, tx, current, active)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService PatchUser", "Error transaction", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return s.GetUser(ctx, userId)
+}
+
+// DeleteUser deactivates the user; the row is kept so the account can be
+// re-provisioned later.
+func (s *scimService) DeleteUser(ctx context.Context, userId uuid.UUID) error {
+	logger.InfoContext(ctx, "scimService DeleteUser", "Executing DeleteUser Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		current, err := s.searchAccount(ctx, s.userRepo.WithTx(tx), userId)
+		if err != nil {
+			return err
+		}
+
+		return s.setActive(ctx, tx, current, false)
+	})
+}
+
+func (s *scimService) ListGroups(ctx context.Context, filter string, startIndex int, count int) (*scim.ListResponse, error) {
+	logger.InfoContext(ctx, "scimService ListGroups", "Executing ListGroups Service", map[string]string{
+		"filter": filter,
+	})
+
+	parsed, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, errs.ScimInvalidFilter
+	}
+
+	name := ""
+	if parsed != nil {
+		if scim.AttributeName(parsed.Attribute) != "displayname" || parsed.Operator != "eq" {
+			return nil, errs.ScimInvalidFilter
+		}
+		name = parsed.Value
+	}
+
+	startIndex, count = scimPage(startIndex, count)
+
+	roles, total, err := s.roleRepo.ListRoles(ctx, name, startIndex-1, count)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make([]*scim.Group, 0, len(roles))
+	for i := range roles {
+		group, err := s.toScimGroup(ctx, s.roleRepo, &roles[i])
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, group)
+	}
+
+	return &scim.ListResponse{
+		Schemas:      []string{scim.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	}, nil
+}
+
+func (s *scimService) GetGroup(ctx context.Context, roleId uuid.UUID) (*scim.Group, error) {
+	role, err := s.searchRole(ctx, s.roleRepo, roleId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toScimGroup(ctx, s.roleRepo, role)
+}
+
+func (s *scimService) CreateGroup(ctx context.Context, input *scim.Group) (*scim.Group, error) {
+	logger.InfoContext(ctx, "scimService CreateGroup", "Executing CreateGroup Service", map[string]string{
+		"displayName": input.DisplayName,
+	})
+
+	role := &model.Role{}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		roleRepoWithTx := s.roleRepo.WithTx(tx)
+
+		existing, _, err := roleRepoWithTx.ListRoles(ctx, input.DisplayName, 0, 1)
+		if err != nil {
+			return err
+		}
+		if len(existing) != 0 {
+			return errs.RoleAlreadyExists
+		}
+
+		role, err = roleRepoWithTx.CreateRole(ctx, input.DisplayName)
+		if err != nil {
+			return err
+		}
+
+		return addMembers(ctx, roleRepoWithTx, role.Name, input.Members)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService CreateGroup", "Error transaction", map[string]string{
+			"displayName": input.DisplayName,
+			"error":       err.Error(),
+		})
+		return nil, err
+	}
+
+	return s.GetGroup(ctx, role.Id)
+}
+
+func (s *scimService) ReplaceGroup(ctx context.Context, roleId uuid.UUID, input *scim.Group) (*scim.Group, error) {
+	logger.InfoContext(ctx, "scimService ReplaceGroup", "Executing ReplaceGroup Service", map[string]string{
+		"roleId": roleId.String(),
+	})
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		roleRepoWithTx := s.roleRepo.WithTx(tx)
+
+		role, err := s.searchRole(ctx, roleRepoWithTx, roleId)
+		if err != nil {
+			return err
+		}
+
+		if err := renameRole(ctx, roleRepoWithTx, role, input.DisplayName); err != nil {
+			return err
+		}
+
+		if err := removeAllMembers(ctx, roleRepoWithTx, role.Name); err != nil {
+			return err
+		}
+
+		return addMembers(ctx, roleRepoWithTx, role.Name, input.Members)
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService ReplaceGroup", "Error transaction", map[string]string{
+			"roleId": roleId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return s.GetGroup(ctx, roleId)
+}
+
+func (s *scimService) PatchGroup(ctx context.Context, roleId uuid.UUID, input *scim.PatchRequest) (*scim.Group, error) {
+	logger.InfoContext(ctx, "scimService PatchGroup", "Executing PatchGroup Service", map[string]string{
+		"roleId": roleId.String(),
+	})
+
+	if err := validateGroupPatch(input); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		roleRepoWithTx := s.roleRepo.WithTx(tx)
+
+		role, err := s.searchRole(ctx, roleRepoWithTx, roleId)
+		if err != nil {
+			return err
+		}
+
+		for _, operation := range input.Operations {
+			op := strings.ToLower(operation.Op)
+
+			attributes, err := patchAttributes(operation)
+			if err != nil {
+				return err
+			}
+
+			for path, value := range attributes {
+				switch scim.AttributeName(path) {
+				case "displayname":
+					if op == "remove" {
+						return errs.ScimInvalidPatch
+					}
+					name, err := scimString(value)
+					if err != nil {
+						return err
+					}
+					err = renameRole(ctx, roleRepoWithTx, role, name)
+					if err != nil {
+						return err
+					}
+				case "members":
+					err = patchMembers(ctx, roleRepoWithTx, role.Name, op, path, value)
+					if err != nil {
+						return err
+					}
+				default:
+					return errs.ScimInvalidPatch
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		logger.ErrorContext(ctx, "scimService PatchGroup", "Error transaction", map[string]string{
+			"roleId": roleId.String(),
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return s.GetGroup(ctx, roleId)
+}
+
+func (s *scimService) DeleteGroup(ctx context.Context, roleId uuid.UUID) error {
+	logger.InfoContext(ctx, "scimService DeleteGroup", "Executing DeleteGroup Service", map[string]string{
+		"roleId": roleId.String(),
+	})
+
+	return s.txManager.Do(ctx, func(ctx context.Context, tx *gorm.DB) error {
+		roleRepoWithTx := s.roleRepo.WithTx(tx)
+
+		role, err := s.searchRole(ctx, roleRepoWithTx, roleId)
+		if err != nil {
+			return err
+		}
+
+		return roleRepoWithTx.DeleteRole(ctx, role.Id, role.Name)
+	})
+}
+
+func (s *scimService) searchAccount(ctx context.Context, userRepo repository.UserRepository, userId uuid.UUID) (*model.UserAccount, error) {
+	account, err := userRepo.SearchUserAccountById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if account.Id == uuid.Nil {
+		return nil, errs.ScimResourceNotFound
+	}
+
+	return account, nil
+}
+
+func (s *scimService) searchRole(ctx context.Context, roleRepo repository.RoleRepository, roleId uuid.UUID) (*model.Role, error) {
+	role, err := roleRepo.SearchRoleById(ctx, roleId)
+	if err != nil {
+		return nil, err
+	}
+	if role.Id == uuid.Nil {
+		return nil, errs.ScimResourceNotFound
+	}
+
+	return role, nil
+}
+
+func (s *scimService) checkUsernameFree(ctx context.Context, authRepo repository.AuthRepository, username string, userId uuid.UUID) error {
+	existing, err := authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: username})
+	if err != nil {
+		return err
+	}
+	if existing.Id != uuid.Nil && existing.Id != userId {
+		return errs.UsernameAlreadyUsed
+	}
+
+	return nil
+}
+
+// setActive deactivates or reactivates the user. Deactivation also revokes
+// every token issued so far.
+func (s *scimService) setActive(ctx context.Context, tx *gorm.DB, current *model.UserAccount, active bool) error {
+	if active == (current.DeletedAt == nil) {
+		return nil
+	}
+
+	if err := s.userRepo.WithTx(tx).SetUserActive(ctx, current.Id, active); err != nil {
+		return err
+	}
+
+	if !active {
+		if err := s.sessionRepo.WithTx(tx).RevokeSessions(ctx, current.Id); err != nil {
+			return err
+		}
+	}
+
+	repository.AfterCommit(ctx, func() {
+		audit.Log(ctx, "user active status changed", map[string]string{
+			"userId": current.Id.String(),
+			"active": strconv.FormatBool(active),
+			"source": roleSourceSCIM,
+		})
+	})
+
+	return nil
+}
+
+func (s *scimService) hashPassword(ctx context.Context, password string) (string, error) {
+	if password == "" {
+		randomPassword, err := securetoken.Generate(32)
+		if err != nil {
+			return "", err
+		}
+		password = randomPassword
+	}
+
+	return s.hasher.Hash(password)
+}
+
+func (s *scimService) updatePassword(ctx context.Context, tx *gorm.DB, userId uuid.UUID, password string) error {
+	hashedPassword, err := s.hasher.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.WithTx(tx).UpdatePassword(ctx, userId, hashedPassword)
+}
+
+func (s *scimService) toScimUser(ctx context.Context, account *model.UserAccount) (*scim.User, error) {
+	roles, err := s.roleRepo.SearchRoleRecordsByUserId(ctx, account.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	active := account.DeletedAt == nil
+
+	user := &scim.User{
+		Schemas:    []string{scim.SchemaUser},
+		Id:         account.Id.String(),
+		ExternalId: account.ExternalId,
+		UserName:   account.Username,
+		Active:     &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      account.CreatedAt,
+			LastModified: account.UpdatedAt,
+			Location:     "/scim/v2/Users/" + account.Id.String(),
+		},
+	}
+
+	if account.Email != "" {
+		user.Emails = []scim.MultiValue{{Value: account.Email, Type: "work", Primary: true}}
+	}
+	if account.PhoneNumber != "" {
+		user.PhoneNumbers = []scim.MultiValue{{Value: account.PhoneNumber, Type: "mobile", Primary: true}}
+	}
+	if account.Address != "" {
+		user.Addresses = []scim.MultiValue{{Formatted: account.Address, Type: "work", Primary: true}}
+	}
+
+	for _, role := range roles {
+		user.Groups = append(user.Groups, scim.MultiValue{Value: role.Id.String(), Display: role.Name})
+	}
+
+	return user, nil
+}
+
+func (s *scimService) toScimGroup(ctx context.Context, roleRepo repository.RoleRepository, role *model.Role) (*scim.Group, error) {
+	members, err := roleRepo.SearchRoleMembers(ctx, role.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		Id:          role.Id.String(),
+		DisplayName: role.Name,
+		Members:     []scim.MultiValue{},
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      role.CreatedAt,
+			LastModified: role.UpdatedAt,
+			Location:     "/scim/v2/Groups/" + role.Id.String(),
+		},
+	}
+
+	for _, member := range members {
+		group.Members = append(group.Members, scim.MultiValue{Value: member.Id.String(), Display: member.Username})
+	}
+
+	return group, nil
+}
+
+func fromScimUser(input *scim.User) *model.UserAccount {
+	address := ""
+	for _, value := range input.Addresses {
+		if value.Primary || address == "" {
+			address = value.Formatted
+		}
+	}
+
+	return &model.UserAccount{
+		Username:    strings.ToLower(input.UserName),
+		ExternalId:  input.ExternalId,
+		Email:       scim.PrimaryValue(input.Emails),
+		PhoneNumber: scim.PrimaryValue(input.PhoneNumbers),
+		Address:     address,
+	}
+}
+
+func toUserFilter(filter string) (*model.UserFilter, error) {
+	parsed, err := scim.ParseFilter(filter)
+	if err != nil {
+		return nil, errs.ScimInvalidFilter
+	}
+	if parsed == nil {
+		return nil, nil
+	}
+
+	attribute := scim.AttributeName(parsed.Attribute)
+
+	if attribute == "active" {
+		active, err := strconv.ParseBool(parsed.Value)
+		if err != nil || (parsed.Operator != "eq" && parsed.Operator != "ne") {
+			return nil, errs.ScimInvalidFilter
+		}
+		if parsed.Operator == "ne" {
+			active = !active
+		}
+
+		operator := "notnull"
+		if active {
+			operator = "null"
+		}
+
+		return &model.UserFilter{Column: "u.deleted_at", Operator: operator}, nil
+	}
+
+	column, ok := scimUserColumns[attribute]
+	if !ok {
+		return nil, errs.ScimInvalidFilter
+	}
+
+	return &model.UserFilter{Column: column, Operator: parsed.Operator, Value: parsed.Value}, nil
+}
+
+// scimPage clamps the requested page. A negative count means the client left
+// it out and gets the default page size, while 0 is kept so that only
+// totalResults is returned.
+func scimPage(startIndex int, count int) (int, int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = scimDefaultCount
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+
+	return startIndex, count
+}
+
+// patchAttributes expands an operation without path, whose value is an
+// object of attributes, into one entry per attribute.
+func patchAttributes(operation scim.PatchOperation) (map[string]interface{}, error) {
+	if operation.Path != "" {
+		return map[string]interface{}{operation.Path: operation.Value}, nil
+	}
+
+	values, ok := operation.Value.(map[string]interface{})
+	if !ok {
+		return nil, errs.ScimInvalidPatch
+	}
+
+	return values, nil
+}
+
+// validateGroupPatch checks every operation of a group patch before any of
+// them is applied, so a request is either applied whole or rejected.
+func validateGroupPatch(input *scim.PatchRequest) error {
+	for _, operation := range input.Operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return errs.ScimInvalidPatch
+		}
+
+		attributes, err := patchAttributes(operation)
+		if err != nil {
+			return err
+		}
+
+		for path, value := range attributes {
+			switch scim.AttributeName(path) {
+			case "displayname":
+				if op == "remove" {
+					return errs.ScimInvalidPatch
+				}
+				if _, err := scimString(value); err != nil {
+					return err
+				}
+			case "members":
+				for _, member := range scimMembers(value) {
+					if _, err := uuid.Parse(member.Value); err != nil {
+						return errs.ScimInvalidValue
+					}
+				}
+				if memberId, ok := scim.ValueFilter(path); ok {
+					if _, err := uuid.Parse(memberId); err != nil {
+						return errs.ScimInvalidValue
+					}
+				}
+			default:
+				return errs.ScimInvalidPatch
+			}
+		}
+	}
+
+	return nil
+}
+
+func patchMembers(ctx context.Context, roleRepo repository.RoleRepository, role string, op string, path string, value interface{}) error {
+	switch op {
+	case "add":
+		return addMembers(ctx, roleRepo, role, scimMembers(value))
+	case "replace":
+		if err := removeAllMembers(ctx, roleRepo, role); err != nil {
+			return err
+		}
+		return addMembers(ctx, roleRepo, role, scimMembers(value))
+	case "remove":
+		if memberId, ok := scim.ValueFilter(path); ok {
+			return removeMembers(ctx, roleRepo, role, []scim.MultiValue{{Value: memberId}})
+		}
+		if value == nil {
+			return removeAllMembers(ctx, roleRepo, role)
+		}
+		return removeMembers(ctx, roleRepo, role, scimMembers(value))
+	default:
+		return errs.ScimInvalidPatch
+	}
+}
+
+func addMembers(ctx context.Context, roleRepo repository.RoleRepository, role string, members []scim.MultiValue) error {
+	for _, member := range members {
+		userId, err := uuid.Parse(member.Value)
+		if err != nil {
+			return errs.ScimInvalidValue
+		}
+
+		if err := roleRepo.AddRoleMember(ctx, userId, role, roleSourceSCIM); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeMembers(ctx context.Context, roleRepo repository.RoleRepository, role string, members []scim.MultiValue) error {
+	for _, member := range members {
+		userId, err := uuid.Parse(member.Value)
+		if err != nil {
+			return errs.ScimInvalidValue
+		}
+
+		if err := roleRepo.RemoveRoleMember(ctx, userId, role); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeAllMembers(ctx context.Context, roleRepo repository.RoleRepository, role string) error {
+	members, err := roleRepo.SearchRoleMembers(ctx, role)
+	if err != nil {
+		return err
+	}
+
+	for _, member := range members {
+		if err := roleRepo.RemoveRoleMember(ctx, member.Id, role); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func renameRole(ctx context.Context, roleRepo repository.RoleRepository, role *model.Role, name string) error {
+	if name == "" || name == role.Name {
+		return nil
+	}
+
+	existing, _, err := roleRepo.ListRoles(ctx, name, 0, 1)
+	if err != nil {
+		return err
+	}
+	if len(existing) != 0 && existing[0].Id != role.Id {
+		return errs.RoleAlreadyExists
+	}
+
+	if err := roleRepo.RenameRole(ctx, role.Id, role.Name, name); err != nil {
+		return err
+	}
+
+	role.Name = name
+
+	return nil
+}
+
+func scimMembers(value interface{}) []scim.MultiValue {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	members := []scim.MultiValue{}
+	for _, item := range items {
+		if object, ok := item.(map[string]interface{}); ok {
+			if memberId, ok := object["value"].(string); ok {
+				members = append(members, scim.MultiValue{Value: memberId})
+			}
+		}
+	}
+
+	return members
+}
+
+func scimString(value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+
+	str, ok := value.(string)
+	if !ok {
+		return "", errs.ScimInvalidValue
+	}
+
+	return str, nil
+}
+
+// scimBool accepts JSON booleans and the "True"/"False" strings some
+// identity providers send.
+func scimBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, errs.ScimInvalidValue
+		}
+		return parsed, nil
+	default:
+		return false, errs.ScimInvalidValue
+	}
+}
+
+// scimMultiValue returns the primary value of a multi-valued attribute given
+// as a plain string, a single object or a list of objects.
+func scimMultiValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case map[string]interface{}:
+		return multiValueField(v), nil
+	case []interface{}:
+		result := ""
+		for _, item := range v {
+			object, ok := item.(map[string]interface{})
+			if !ok {
+				return "", errs.ScimInvalidValue
+			}
+			if primary, _ := object["primary"].(bool); primary || result == "" {
+				result = multiValueField(object)
+			}
+		}
+		return result, nil
+	default:
+		return "", errs.ScimInvalidValue
+	}
+}
+
+func multiValueField(object map[string]interface{}) string {
+	if value, ok := object["value"].(string); ok {
+		return value
+	}
+	if formatted, ok := object["formatted"].(string); ok {
+		return formatted
+	}
+
+	return ""
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/util/scim"
+)
+
+const testMemberId = "2819c223-7f76-453a-919d-413861904646"
+
+func TestToUserFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    *model.UserFilter
+		wantErr error
+	}{
+		{name: "no filter", filter: "", want: nil},
+		{name: "username", filter: `userName eq "Alice"`, want: &model.UserFilter{Column: "u.username", Operator: "eq", Value: "Alice"}},
+		{name: "email sub-attribute", filter: `emails.value co "@kfc"`, want: &model.UserFilter{Column: "ud.email", Operator: "co", Value: "@kfc"}},
+		{name: "external id present", filter: "externalId pr", want: &model.UserFilter{Column: "u.external_id", Operator: "pr"}},
+		{name: "active", filter: "active eq true", want: &model.UserFilter{Column: "u.deleted_at", Operator: "null"}},
+		{name: "inactive", filter: "active eq false", want: &model.UserFilter{Column: "u.deleted_at", Operator: "notnull"}},
+		{name: "not active", filter: "active ne true", want: &model.UserFilter{Column: "u.deleted_at", Operator: "notnull"}},
+		{name: "active with other operator", filter: "active sw true", wantErr: errs.ScimInvalidFilter},
+		{name: "active with non boolean", filter: `active eq "yes"`, wantErr: errs.ScimInvalidFilter},
+		{name: "unknown attribute", filter: `password eq "x"`, wantErr: errs.ScimInvalidFilter},
+		{name: "malformed", filter: "userName", wantErr: errs.ScimInvalidFilter},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := toUserFilter(tt.filter)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("toUserFilter(%q) error = %v, want %v", tt.filter, err, tt.wantErr)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("toUserFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScimPage(t *testing.T) {
+	tests := []struct {
+		name                 string
+		startIndex, count    int
+		wantStart, wantCount int
+	}{
+		{name: "defaults", startIndex: 0, count: -1, wantStart: 1, wantCount: scimDefaultCount},
+		{name: "kept", startIndex: 5, count: 10, wantStart: 5, wantCount: 10},
+		{name: "totals only", startIndex: 1, count: 0, wantStart: 1, wantCount: 0},
+		{name: "clamped", startIndex: -3, count: scimMaxCount + 1, wantStart: 1, wantCount: scimMaxCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, count := scimPage(tt.startIndex, tt.count)
+			if start != tt.wantStart || count != tt.wantCount {
+				t.Errorf("scimPage(%d, %d) = %d, %d, want %d, %d", tt.startIndex, tt.count, start, count, tt.wantStart, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestPatchAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation scim.PatchOperation
+		want      []string
+		wantErr   error
+	}{
+		{name: "path", operation: scim.PatchOperation{Op: "replace", Path: "active", Value: false}, want: []string{"active"}},
+		{name: "object", operation: scim.PatchOperation{Op: "replace", Value: map[string]interface{}{"active": false, "userName": "bob"}}, want: []string{"active", "userName"}},
+		{name: "no path nor object", operation: scim.PatchOperation{Op: "replace", Value: "bob"}, wantErr: errs.ScimInvalidPatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchAttributes(tt.operation)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("patchAttributes() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("patchAttributes() = %v, want keys %v", got, tt.want)
+			}
+			for _, key := range tt.want {
+				if _, ok := got[key]; !ok {
+					t.Errorf("patchAttributes() = %v, missing %q", got, key)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateGroupPatch(t *testing.T) {
+	member := map[string]interface{}{"value": testMemberId}
+
+	tests := []struct {
+		name       string
+		operations []scim.PatchOperation
+		wantErr    error
+	}{
+		{
+			name: "rename and add members",
+			operations: []scim.PatchOperation{
+				{Op: "Replace", Path: "displayName", Value: "Cashiers"},
+				{Op: "add", Path: "members", Value: []interface{}{member}},
+			},
+		},
+		{
+			name:       "remove one member",
+			operations: []scim.PatchOperation{{Op: "remove", Path: `members[value eq "` + testMemberId + `"]`}},
+		},
+		{
+			name:       "replace without path",
+			operations: []scim.PatchOperation{{Op: "replace", Value: map[string]interface{}{"displayName": "Cashiers"}}},
+		},
+		{
+			name: "unsupported op after a valid one",
+			operations: []scim.PatchOperation{
+				{Op: "add", Path: "members", Value: []interface{}{member}},
+				{Op: "move", Path: "members"},
+			},
+			wantErr: errs.ScimInvalidPatch,
+		},
+		{
+			name:       "remove display name",
+			operations: []scim.PatchOperation{{Op: "remove", Path: "displayName"}},
+			wantErr:    errs.ScimInvalidPatch,
+		},
+		{
+			name:       "unknown attribute",
+			operations: []scim.PatchOperation{{Op: "replace", Path: "description", Value: "x"}},
+			wantErr:    errs.ScimInvalidPatch,
+		},
+		{
+			name:       "display name not a string",
+			operations: []scim.PatchOperation{{Op: "replace", Path: "displayName", Value: 1}},
+			wantErr:    errs.ScimInvalidValue,
+		},
+		{
+			name:       "member id not a uuid",
+			operations: []scim.PatchOperation{{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": "bob"}}}},
+			wantErr:    errs.ScimInvalidValue,
+		},
+		{
+			name:       "member filter not a uuid",
+			operations: []scim.PatchOperation{{Op: "remove", Path: `members[value eq "bob"]`}},
+			wantErr:    errs.ScimInvalidValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateGroupPatch(&scim.PatchRequest{Operations: tt.operations})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("validateGroupPatch() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/google/uuid"
+)
+
+type SessionService interface {
+	IsRevoked(ctx context.Context, userId string, issuedAt time.Time) (bool, error)
+	RevokeSessions(ctx context.Context, userId uuid.UUID) error
+}
+
+type sessionService struct {
+	sessionRepo repository.SessionRepository
+}
+
+type SessionServiceConfig struct {
+	SessionRepo repository.SessionRepository
+}
+
+func NewSessionService(config SessionServiceConfig) SessionService {
+	return &sessionService{
+		sessionRepo: config.SessionRepo,
+	}
+}
+
+// IsRevoked reports whether a token issued at issuedAt must be rejected
+// because the user was deactivated or its sessions were revoked afterwards.
+func (s *sessionService) IsRevoked(ctx context.Context, userId string, issuedAt time.Time) (bool, error) {
+	parsedUserId, err := uuid.Parse(userId)
+	if err != nil {
+		return true, nil
+	}
+
+	state, err := s.sessionRepo.SearchSessionState(ctx, parsedUserId)
+	if err != nil {
+		return false, err
+	}
+
+	if state.Id == uuid.Nil || state.DeletedAt != nil {
+		return true, nil
+	}
+
+	// Token timestamps have second precision, so a token issued in the same
+	// second as the revocation is treated as revoked.
+	if state.SessionsRevokedAt != nil && !issuedAt.After(state.SessionsRevokedAt.Truncate(time.Second)) {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (s *sessionService) RevokeSessions(ctx context.Context, userId uuid.UUID) error {
+	logger.InfoContext(ctx, "sessionService RevokeSessions", "Executing RevokeSessions Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return s.sessionRepo.RevokeSessions(ctx, userId)
+}
@@ -0,0 +1,437 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/dto"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/model"
+	"github.com/EputraP/kfc_be/internal/repository"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
+)
+
+const (
+	webAuthnCeremonyRegistration = "registration"
+	webAuthnCeremonyLogin        = "login"
+)
+
+type WebAuthnService interface {
+	BeginRegistration(ctx context.Context, userId uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error)
+	FinishRegistration(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID, parsed *protocol.ParsedCredentialCreationData) error
+	BeginLogin(ctx context.Context, input *dto.WebAuthnLoginBeginBody) (*protocol.CredentialAssertion, uuid.UUID, error)
+	FinishLogin(ctx context.Context, sessionId uuid.UUID, parsed *protocol.ParsedCredentialAssertionData) (*dto.LoginResponse, error)
+}
+
+type webAuthnService struct {
+	authRepo          repository.AuthRepository
+	webAuthnRepo      repository.WebAuthnRepository
+	jtwProvider       tokenprovider.JWTTokenProvider
+	organizationRepo  repository.OrganizationRepository
+	webAuthn          *webauthn.WebAuthn
+	challengeDuration int
+}
+
+type WebAuthnServiceConfig struct {
+	AuthRepo         repository.AuthRepository
+	WebAuthnRepo     repository.WebAuthnRepository
+	JwtProvider      tokenprovider.JWTTokenProvider
+	OrganizationRepo repository.OrganizationRepository
+	// WebAuthn is the relying party, nil when passkeys are disabled.
+	WebAuthn *webauthn.WebAuthn
+	// ChallengeDuration is the lifetime of a ceremony challenge in minutes.
+	ChallengeDuration int
+}
+
+func NewWebAuthnService(config WebAuthnServiceConfig) WebAuthnService {
+	return &webAuthnService{
+		authRepo:          config.AuthRepo,
+		webAuthnRepo:      config.WebAuthnRepo,
+		jtwProvider:       config.JwtProvider,
+		organizationRepo:  config.OrganizationRepo,
+		webAuthn:          config.WebAuthn,
+		challengeDuration: config.ChallengeDuration,
+	}
+}
+
+func (s *webAuthnService) BeginRegistration(ctx context.Context, userId uuid.UUID) (*protocol.CredentialCreation, uuid.UUID, error) {
+	logger.InfoContext(ctx, "webAuthnService BeginRegistration", "Executing BeginRegistration Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	if s.webAuthn == nil {
+		return nil, uuid.Nil, errs.WebAuthnDisabled
+	}
+
+	user, err := s.loadUser(ctx, userId)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, credential := range user.WebAuthnCredentials() {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	options, session, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "webAuthnService BeginRegistration", "Error beginning registration", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return nil, uuid.Nil, err
+	}
+
+	sessionId, err := s.saveSession(ctx, &userId, webAuthnCeremonyRegistration, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	logger.InfoContext(ctx, "webAuthnService BeginRegistration", "Finished BeginRegistration Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return options, sessionId, nil
+}
+
+func (s *webAuthnService) FinishRegistration(ctx context.Context, userId uuid.UUID, sessionId uuid.UUID, parsed *protocol.ParsedCredentialCreationData) error {
+	logger.InfoContext(ctx, "webAuthnService FinishRegistration", "Executing FinishRegistration Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	if s.webAuthn == nil {
+		return errs.WebAuthnDisabled
+	}
+
+	challengeUserId, session, err := s.consumeSession(ctx, sessionId, webAuthnCeremonyRegistration)
+	if err != nil {
+		return err
+	}
+	if challengeUserId == nil || *challengeUserId != userId {
+		logger.ErrorContext(ctx, "webAuthnService FinishRegistration", errs.InvalidWebAuthnSession.Error(), map[string]string{
+			"userId": userId.String(),
+		})
+		return errs.InvalidWebAuthnSession
+	}
+
+	user, err := s.loadUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *session, parsed)
+	if err != nil {
+		logger.ErrorContext(ctx, "webAuthnService FinishRegistration", errs.WebAuthnVerificationFailed.Error(), map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return errs.WebAuthnVerificationFailed
+	}
+
+	_, err = s.webAuthnRepo.CreateCredential(ctx, fromWebAuthnCredential(userId, credential))
+	if err != nil {
+		logger.ErrorContext(ctx, "webAuthnService FinishRegistration", "Error storing credential", map[string]string{
+			"userId": userId.String(),
+			"error":  err.Error(),
+		})
+		return err
+	}
+
+	logger.InfoContext(ctx, "webAuthnService FinishRegistration", "Finished FinishRegistration Service", map[string]string{
+		"userId": userId.String(),
+	})
+
+	return nil
+}
+
+// BeginLogin starts an assertion for the given username, or a discoverable
+// (passkey) assertion when no username is sent.
+func (s *webAuthnService) BeginLogin(ctx context.Context, input *dto.WebAuthnLoginBeginBody) (*protocol.CredentialAssertion, uuid.UUID, error) {
+	logger.InfoContext(ctx, "webAuthnService BeginLogin", "Executing BeginLogin Service", map[string]string{
+		"username": input.Username,
+	})
+
+	if s.webAuthn == nil {
+		return nil, uuid.Nil, errs.WebAuthnDisabled
+	}
+
+	var options *protocol.CredentialAssertion
+	var session *webauthn.SessionData
+	var userId *uuid.UUID
+
+	if input.Username == "" {
+		var err error
+		options, session, err = s.webAuthn.BeginDiscoverableLogin()
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+	} else {
+		account, err := s.authRepo.SearchUserByUsername(ctx, &dto.RegisterBody{Username: strings.ToLower(input.Username)})
+		if err != nil {
+			logger.ErrorContext(ctx, "webAuthnService BeginLogin", errs.SearchUsernameError.Error(), map[string]string{
+				"username": input.Username,
+				"error":    err.Error(),
+			})
+			return nil, uuid.Nil, errs.SearchUsernameError
+		}
+		if account.Id == uuid.Nil {
+			return nil, uuid.Nil, errs.WebAuthnNoCredentials
+		}
+
+		user, err := s.loadUser(ctx, account.Id)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		if len(user.credentials) == 0 {
+			return nil, uuid.Nil, errs.WebAuthnNoCredentials
+		}
+
+		options, session, err = s.webAuthn.BeginLogin(user)
+		if err != nil {
+			return nil, uuid.Nil, err
+		}
+		userId = &account.Id
+	}
+
+	sessionId, err := s.saveSession(ctx, userId, webAuthnCeremonyLogin, session)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+
+	logger.InfoContext(ctx, "webAuthnService BeginLogin", "Finished BeginLogin Service", map[string]string{
+		"username": input.Username,
+	})
+
+	return options, sessionId, nil
+}
+
+func (s *webAuthnService) FinishLogin(ctx context.Context, sessionId uuid.UUID, parsed *protocol.ParsedCredentialAssertionData) (*dto.LoginResponse, error) {
+	logger.InfoContext(ctx, "webAuthnService FinishLogin", "Executing FinishLogin Service", nil)
+
+	if s.webAuthn == nil {
+		return nil, errs.WebAuthnDisabled
+	}
+
+	challengeUserId, session, err := s.consumeSession(ctx, sessionId, webAuthnCeremonyLogin)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *webAuthnUser
+	var credential *webauthn.Credential
+
+	if challengeUserId != nil {
+		user, err = s.loadUser(ctx, *challengeUserId)
+		if err != nil {
+			return nil, err
+		}
+
+		credential, err = s.webAuthn.ValidateLogin(user, *session, parsed)
+	} else {
+		credential, err = s.webAuthn.ValidateDiscoverableLogin(func(rawID, userHandle []byte) (webauthn.User, error) {
+			userId, err := uuid.FromBytes(userHandle)
+			if err != nil {
+				return nil, err
+			}
+
+			user, err = s.loadUser(ctx, userId)
+			return user, err
+		}, *session, parsed)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "webAuthnService FinishLogin", errs.WebAuthnVerificationFailed.Error(), map[string]string{
+			"error": err.Error(),
+		})
+		return nil, errs.WebAuthnVerificationFailed
+	}
+
+	stored := user.findCredential(credential.ID)
+	if stored == nil {
+		return nil, errs.WebAuthnVerificationFailed
+	}
+
+	stored.SignCount = int64(credential.Authenticator.SignCount)
+	stored.BackupState = credential.Flags.BackupState
+	stored.CloneWarning = stored.CloneWarning || credential.Authenticator.CloneWarning
+
+	if err := s.webAuthnRepo.UpdateCredentialUsage(ctx, stored); err != nil {
+		return nil, err
+	}
+
+	if credential.Authenticator.CloneWarning {
+		logger.ErrorContext(ctx, "webAuthnService FinishLogin", errs.WebAuthnCloneDetected.Error(), map[string]string{
+			"userId":       user.user.Id.String(),
+			"credentialId": stored.Id.String(),
+		})
+		return nil, errs.WebAuthnCloneDetected
+	}
+
+	loginResponse, err := generateLoginResponse(ctx, s.jtwProvider, s.organizationRepo, user.user)
+	if err != nil {
+		logger.ErrorContext(ctx, "webAuthnService FinishLogin", errs.GenerateLoginResponseError.Error(), map[string]string{
+			"userId": user.user.Id.String(),
+			"error":  err.Error(),
+		})
+		return nil, errs.GenerateLoginResponseError
+	}
+
+	logger.InfoContext(ctx, "webAuthnService FinishLogin", "Finished FinishLogin Service", map[string]string{
+		"userId": user.user.Id.String(),
+	})
+
+	return loginResponse, nil
+}
+
+func (s *webAuthnService) loadUser(ctx context.Context, userId uuid.UUID) (*webAuthnUser, error) {
+	user, err := s.authRepo.SearchUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if user.Id == uuid.Nil {
+		return nil, errs.WebAuthnNoCredentials
+	}
+
+	credentials, err := s.webAuthnRepo.SearchCredentialsByUserId(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &webAuthnUser{user: user, credentials: credentials}, nil
+}
+
+func (s *webAuthnService) saveSession(ctx context.Context, userId *uuid.UUID, ceremony string, session *webauthn.SessionData) (uuid.UUID, error) {
+	sessionData, err := json.Marshal(session)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	challenge, err := s.webAuthnRepo.CreateChallenge(ctx, &model.WebAuthnChallenge{
+		UserId:      userId,
+		Ceremony:    ceremony,
+		SessionData: sessionData,
+		ExpiresAt:   time.Now().Add(time.Duration(s.challengeDuration) * time.Minute),
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return challenge.Id, nil
+}
+
+func (s *webAuthnService) consumeSession(ctx context.Context, sessionId uuid.UUID, ceremony string) (*uuid.UUID, *webauthn.SessionData, error) {
+	challenge, err := s.webAuthnRepo.ConsumeChallenge(ctx, sessionId, ceremony)
+	if err != nil {
+		return nil, nil, err
+	}
+	if challenge.Id == uuid.Nil {
+		logger.ErrorContext(ctx, "webAuthnService consumeSession", errs.InvalidWebAuthnSession.Error(), map[string]string{
+			"sessionId": sessionId.String(),
+		})
+		return nil, nil, errs.InvalidWebAuthnSession
+	}
+
+	session := &webauthn.SessionData{}
+	if err := json.Unmarshal(challenge.SessionData, session); err != nil {
+		return nil, nil, err
+	}
+
+	return challenge.UserId, session, nil
+}
+
+// webAuthnUser adapts a user and its stored credentials to webauthn.User.
+type webAuthnUser struct {
+	user        *model.User
+	credentials []model.WebAuthnCredential
+}
+
+func (u *webAuthnUser) WebAuthnID() []byte {
+	id := u.user.Id
+	return id[:]
+}
+
+func (u *webAuthnUser) WebAuthnName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.user.Username
+}
+
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.credentials))
+	for _, credential := range u.credentials {
+		credentials = append(credentials, toWebAuthnCredential(credential))
+	}
+
+	return credentials
+}
+
+func (u *webAuthnUser) findCredential(credentialId []byte) *model.WebAuthnCredential {
+	for i := range u.credentials {
+		if bytes.Equal(u.credentials[i].CredentialId, credentialId) {
+			return &u.credentials[i]
+		}
+	}
+
+	return nil
+}
+
+func toWebAuthnCredential(credential model.WebAuthnCredential) webauthn.Credential {
+	var transports []protocol.AuthenticatorTransport
+	if credential.Transports != "" {
+		for _, transport := range strings.Split(credential.Transports, ",") {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+	}
+
+	return webauthn.Credential{
+		ID:              credential.CredentialId,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			UserPresent:    credential.UserPresent,
+			UserVerified:   credential.UserVerified,
+			BackupEligible: credential.BackupEligible,
+			BackupState:    credential.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       credential.AAGUID,
+			SignCount:    uint32(credential.SignCount),
+			CloneWarning: credential.CloneWarning,
+			Attachment:   protocol.AuthenticatorAttachment(credential.Attachment),
+		},
+	}
+}
+
+func fromWebAuthnCredential(userId uuid.UUID, credential *webauthn.Credential) *model.WebAuthnCredential {
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	return &model.WebAuthnCredential{
+		UserId:          userId,
+		CredentialId:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       int64(credential.Authenticator.SignCount),
+		Transports:      strings.Join(transports, ","),
+		Attachment:      string(credential.Authenticator.Attachment),
+		UserPresent:     credential.Flags.UserPresent,
+		UserVerified:    credential.Flags.UserVerified,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+}
@@ -1,37 +1,39 @@
 package dbstore
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
 	"sync"
 
+	"github.com/EputraP/kfc_be/internal/config"
 	logs "github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+var errNotConnected = errors.New("database is not connected")
+
 var (
-	once sync.Once
-	db   *gorm.DB
+	once    sync.Once
+	db      *gorm.DB
+	dbName  string
+	initErr error
 )
 
-func connectDB() (*gorm.DB, error) {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	pass := os.Getenv("DB_PASS")
-	dbName := os.Getenv("DB_NAME")
-
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", host, port, user, pass, dbName)
+func connectDB(config config.Database) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s", config.Host, config.Port, config.User, config.Password, config.Name)
+	if config.TimeZone != "" {
+		dsn += fmt.Sprintf(" TimeZone=%s", config.TimeZone)
+	}
 
-	log.Println("Connecting with DSN: ", dsn)
 	logs.Info("connectDB", "Connecting with DSN: ", map[string]string{
 		"dsn": dsn,
 	})
 	dbConn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: logs.NewGormLogger(),
 	})
 	if err != nil {
 		logs.Error("connectDB", "error gorm.Open: ", map[string]string{
@@ -40,27 +42,73 @@ func connectDB() (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := dbConn.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+
 	return dbConn, err
 }
 
-func connect() {
-	dbConn, err := connectDB()
+// Init connects to the database described by config. Only the first call
+// connects; later calls return its result.
+func Init(config config.Database) error {
+	once.Do(func() {
+		db, initErr = connectDB(config)
+		dbName = config.Name
+		if initErr != nil {
+			return
+		}
+
+		logs.Info("connectDB", "Success connecting to db", nil)
+	})
+
+	return initErr
+}
+
+// Get returns the connection opened by Init.
+func Get() *gorm.DB {
+	return db
+}
+
+// Close closes the connection pool opened by Init, if any.
+func Close() error {
+	if db == nil {
+		return nil
+	}
 
+	sqlDB, err := db.DB()
 	if err != nil {
-		logs.Error("connect", "error dbConn.DB: ", map[string]string{
-			"error": err.Error(),
-		})
+		return err
+	}
+
+	return sqlDB.Close()
+}
+
+// Ping checks that the connection opened by Init can reach the database.
+func Ping(ctx context.Context) error {
+	if Get() == nil {
+		return errNotConnected
 	}
 
-	logs.Info("connectDB", "Success connecting to db", nil)
+	sqlDB, err := Get().DB()
+	if err != nil {
+		return err
+	}
 
-	db = dbConn
+	return sqlDB.PingContext(ctx)
 }
 
-func Get() *gorm.DB {
-	once.Do(func() {
-		connect()
-	})
+// RegisterMetrics exports the connection pool statistics of the connection
+// opened by Init.
+func RegisterMetrics() error {
+	if Get() == nil {
+		return errNotConnected
+	}
 
-	return db
+	sqlDB, err := Get().DB()
+	if err != nil {
+		return err
+	}
+
+	return metrics.RegisterDBStats(sqlDB, dbName)
 }
@@ -0,0 +1,61 @@
+package apiversion
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// Unversioned is the version of the paths without a prefix, served as
+	// aliases of V1 while clients migrate.
+	Unversioned = ""
+	V1          = "v1"
+
+	// Latest is the version new clients should use.
+	Latest = V1
+)
+
+// Version is an API version mounted under its name, e.g. "/v1". Deprecated
+// versions answer with Deprecation and Sunset headers and a Link to the same
+// route in Successor.
+type Version struct {
+	Name string
+	// Deprecation is when the version was deprecated, zero when it is not.
+	Deprecation time.Time
+	// Sunset is when the version stops being served, zero when undecided.
+	Sunset    time.Time
+	Successor string
+}
+
+// Prefix returns the path prefix of the version, empty for Unversioned.
+func (v Version) Prefix() string {
+	return Prefix(v.Name)
+}
+
+func (v Version) Deprecated() bool {
+	return !v.Deprecation.IsZero()
+}
+
+// Prefix returns the path prefix of the version named name.
+func Prefix(name string) string {
+	if name == Unversioned {
+		return ""
+	}
+
+	return "/" + name
+}
+
+type contextKey struct{}
+
+func WithVersion(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, contextKey{}, name)
+}
+
+// FromContext returns the version stored by WithVersion, or Latest.
+func FromContext(ctx context.Context) string {
+	if name, ok := ctx.Value(contextKey{}).(string); ok {
+		return name
+	}
+
+	return Latest
+}
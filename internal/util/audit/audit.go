@@ -0,0 +1,14 @@
+package audit
+
+import (
+	"context"
+
+	"github.com/EputraP/kfc_be/internal/util/logger"
+)
+
+// Log records a security relevant event. Audit entries share the application
+// log and are told apart by the "audit" message. They are written even when
+// the log level is raised above info.
+func Log(ctx context.Context, event string, details map[string]string) {
+	logger.AuditContext(ctx, "audit", event, details)
+}
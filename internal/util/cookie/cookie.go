@@ -0,0 +1,121 @@
+package cookie
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/gin-gonic/gin"
+)
+
+type Config struct {
+	// Enabled lets the auth middleware read tokens from cookies when no
+	// Authorization header is sent.
+	Enabled  bool
+	Domain   string
+	Path     string
+	SameSite http.SameSite
+	Secure   bool
+
+	// Lifetimes in seconds, derived from the token durations.
+	AccessTokenMaxAge  int
+	RefreshTokenMaxAge int
+}
+
+func NewConfig(enabled bool, domain string, path string, sameSite string, secure bool, accessTokenDuration int, refreshTokenDuration int) Config {
+	if path == "" {
+		path = "/"
+	}
+
+	return Config{
+		Enabled:            enabled,
+		Domain:             domain,
+		Path:               path,
+		SameSite:           ParseSameSite(sameSite),
+		Secure:             secure,
+		AccessTokenMaxAge:  accessTokenDuration * 60,
+		RefreshTokenMaxAge: refreshTokenDuration * 60,
+	}
+}
+
+func ParseSameSite(value string) http.SameSite {
+	switch strings.ToLower(value) {
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteLaxMode
+	}
+}
+
+// SetTokens writes the access and refresh tokens as HttpOnly cookies together
+// with a readable CSRF token for the double-submit check.
+func SetTokens(ctx *gin.Context, config Config, accessToken string, refreshToken string) error {
+	csrfToken, err := NewCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	ctx.SetSameSite(config.SameSite)
+	ctx.SetCookie(constant.CookieRefreshToken, refreshToken, config.RefreshTokenMaxAge, config.Path, config.Domain, config.Secure, true)
+	ctx.SetCookie(constant.CookieAccessToken, accessToken, config.AccessTokenMaxAge, config.Path, config.Domain, config.Secure, true)
+	ctx.SetCookie(constant.CookieCSRFToken, csrfToken, config.RefreshTokenMaxAge, config.Path, config.Domain, config.Secure, false)
+
+	return nil
+}
+
+func SetAccessToken(ctx *gin.Context, config Config, accessToken string) {
+	ctx.SetSameSite(config.SameSite)
+	ctx.SetCookie(constant.CookieAccessToken, accessToken, config.AccessTokenMaxAge, config.Path, config.Domain, config.Secure, true)
+}
+
+func Clear(ctx *gin.Context, config Config) {
+	ctx.SetSameSite(config.SameSite)
+	ctx.SetCookie(constant.CookieRefreshToken, "", -1, config.Path, config.Domain, config.Secure, true)
+	ctx.SetCookie(constant.CookieAccessToken, "", -1, config.Path, config.Domain, config.Secure, true)
+	ctx.SetCookie(constant.CookieCSRFToken, "", -1, config.Path, config.Domain, config.Secure, false)
+}
+
+// Set writes a short-lived HttpOnly cookie using the configured domain, path
+// and SameSite policy. A negative maxAge deletes the cookie.
+func Set(ctx *gin.Context, config Config, name string, value string, maxAge int) {
+	ctx.SetSameSite(config.SameSite)
+	ctx.SetCookie(name, value, maxAge, config.Path, config.Domain, config.Secure, true)
+}
+
+func NewCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidCSRF compares the CSRF cookie against the X-XSRF-TOKEN header.
+func ValidCSRF(ctx *gin.Context) bool {
+	cookieToken, err := ctx.Cookie(constant.CookieCSRFToken)
+	if err != nil || cookieToken == "" {
+		return false
+	}
+
+	headerToken := ctx.GetHeader(constant.HeaderCSRFToken)
+	if headerToken == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) == 1
+}
+
+func IsSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,19 @@
+package directory
+
+import "errors"
+
+var (
+	ErrInvalidCredentials = errors.New("invalid directory credentials")
+	ErrUserNotFound       = errors.New("user not found in directory")
+)
+
+// Entry is the directory account of an authenticated user.
+type Entry struct {
+	DN     string
+	Email  string
+	Groups []string
+}
+
+type Directory interface {
+	Authenticate(username string, password string) (*Entry, error)
+}
@@ -0,0 +1,105 @@
+package directory
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+type LDAPConfig struct {
+	URL          string
+	StartTLS     bool
+	BindDN       string
+	BindPassword string
+	BaseDN       string
+	// UserFilter is formatted with the escaped username, e.g. "(sAMAccountName=%s)".
+	UserFilter     string
+	EmailAttribute string
+	GroupAttribute string
+}
+
+type ldapDirectory struct {
+	config LDAPConfig
+}
+
+func NewLDAP(config LDAPConfig) Directory {
+	if config.UserFilter == "" {
+		config.UserFilter = "(sAMAccountName=%s)"
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+
+	return &ldapDirectory{
+		config: config,
+	}
+}
+
+// Authenticate looks the user up with the service account and then binds as
+// the user to verify the password.
+func (d *ldapDirectory) Authenticate(username string, password string) (*Entry, error) {
+	// An empty password would be an unauthenticated bind, which most
+	// directories accept.
+	if password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	conn, err := ldap.DialURL(d.config.URL, ldap.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	conn.SetTimeout(10 * time.Second)
+
+	if d.config.StartTLS {
+		if err := conn.StartTLS(&tls.Config{MinVersion: tls.VersionTLS12}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := conn.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+		return nil, err
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		d.config.BaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2,
+		10,
+		false,
+		fmt.Sprintf(d.config.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"dn", d.config.EmailAttribute, d.config.GroupAttribute},
+		nil,
+	)
+
+	result, err := conn.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Entries) != 1 {
+		return nil, ErrUserNotFound
+	}
+
+	entry := result.Entries[0]
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	return &Entry{
+		DN:     entry.DN,
+		Email:  entry.GetAttributeValue(d.config.EmailAttribute),
+		Groups: entry.GetAttributeValues(d.config.GroupAttribute),
+	}, nil
+}
@@ -0,0 +1,17 @@
+package health
+
+import (
+	"context"
+)
+
+// Checker probes a dependency and returns an error when it is unusable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to Checker.
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
@@ -0,0 +1,175 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/id"
+	ut "github.com/go-playground/universal-translator"
+	"golang.org/x/text/language"
+)
+
+const (
+	English    = "en"
+	Indonesian = "id"
+)
+
+// Supported lists the languages responses can be served in.
+var Supported = []string{English, Indonesian}
+
+// The English texts live next to the code that uses them: error messages in
+// internal/errors and response messages at their response.JSON call. The
+// catalog of another language translates errors keyed by their code and
+// messages keyed by their English text; anything missing is served in
+// English. Validation messages are keyed by validator tag, {0} being the
+// field and {1} the rule parameter.
+//
+//go:embed locales/*.json
+var catalogFS embed.FS
+
+type catalog struct {
+	Errors     map[string]string `json:"errors"`
+	Messages   map[string]string `json:"messages"`
+	Validation map[string]string `json:"validation"`
+}
+
+type contextKey struct{}
+
+var (
+	defaultLanguage = English
+	catalogs        = map[string]catalog{}
+	matcher         = language.NewMatcher([]language.Tag{language.English, language.Indonesian})
+	universal       = ut.New(en.New(), en.New(), id.New())
+)
+
+// Translatable is implemented by error details that hold messages, so that
+// they are served in the language of the request.
+type Translatable interface {
+	Translate(lang string) interface{}
+}
+
+type Config struct {
+	// DefaultLanguage is used when Accept-Language matches no supported
+	// language.
+	DefaultLanguage string
+}
+
+// Init loads the catalogs. Until it runs everything is served in English. It
+// must run before validation.Register.
+func Init(config Config) error {
+	if !isSupported(config.DefaultLanguage) {
+		return fmt.Errorf("unsupported language %q", config.DefaultLanguage)
+	}
+
+	loaded := map[string]catalog{}
+	for _, lang := range Supported {
+		content, err := catalogFS.ReadFile("locales/" + lang + ".json")
+		if err != nil {
+			return err
+		}
+
+		var c catalog
+		if err := json.Unmarshal(content, &c); err != nil {
+			return fmt.Errorf("parsing %s catalog: %w", lang, err)
+		}
+
+		loaded[lang] = c
+	}
+
+	tags := []language.Tag{language.Make(config.DefaultLanguage)}
+	for _, lang := range Supported {
+		if lang != config.DefaultLanguage {
+			tags = append(tags, language.Make(lang))
+		}
+	}
+
+	defaultLanguage = config.DefaultLanguage
+	catalogs = loaded
+	matcher = language.NewMatcher(tags)
+
+	return nil
+}
+
+// Negotiate returns the supported language that best matches an
+// Accept-Language header.
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return defaultLanguage
+	}
+
+	tag, _, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return defaultLanguage
+	}
+
+	base, _ := tag.Base()
+	if !isSupported(base.String()) {
+		return defaultLanguage
+	}
+
+	return base.String()
+}
+
+// Default returns the language used when the request does not pick one.
+func Default() string {
+	return defaultLanguage
+}
+
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the language stored by WithLanguage, or the default.
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok {
+		return lang
+	}
+
+	return defaultLanguage
+}
+
+// Error returns the message of the error code in lang, or fallback.
+func Error(lang string, code string, fallback string) string {
+	if message, ok := catalogs[lang].Errors[code]; ok {
+		return message
+	}
+
+	return fallback
+}
+
+// Message translates an English response message to lang.
+func Message(lang string, message string) string {
+	if translated, ok := catalogs[lang].Messages[message]; ok {
+		return translated
+	}
+
+	return message
+}
+
+// ValidationMessages returns the validation messages of the lang catalog,
+// keyed by validator tag.
+func ValidationMessages(lang string) map[string]string {
+	return catalogs[lang].Validation
+}
+
+// Translator returns the universal-translator of lang, used for validation
+// messages.
+func Translator(lang string) ut.Translator {
+	trans, _ := universal.GetTranslator(lang)
+
+	return trans
+}
+
+func isSupported(lang string) bool {
+	for _, supported := range Supported {
+		if lang == supported {
+			return true
+		}
+	}
+
+	return false
+}
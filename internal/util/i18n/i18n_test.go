@@ -0,0 +1,72 @@
+package i18n_test
+
+import (
+	"testing"
+
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name            string
+		defaultLanguage string
+		acceptLanguage  string
+		want            string
+	}{
+		{name: "no header", defaultLanguage: i18n.English, acceptLanguage: "", want: i18n.English},
+		{name: "no header with indonesian default", defaultLanguage: i18n.Indonesian, acceptLanguage: "", want: i18n.Indonesian},
+		{name: "indonesian", defaultLanguage: i18n.English, acceptLanguage: "id", want: i18n.Indonesian},
+		{name: "regional tag", defaultLanguage: i18n.English, acceptLanguage: "id-ID", want: i18n.Indonesian},
+		{name: "english region", defaultLanguage: i18n.Indonesian, acceptLanguage: "en-GB", want: i18n.English},
+		{name: "quality order", defaultLanguage: i18n.English, acceptLanguage: "en;q=0.5, id;q=0.9", want: i18n.Indonesian},
+		{name: "first supported", defaultLanguage: i18n.English, acceptLanguage: "fr-FR, id;q=0.8, en;q=0.5", want: i18n.Indonesian},
+		{name: "malay falls back to indonesian", defaultLanguage: i18n.English, acceptLanguage: "ms", want: i18n.Indonesian},
+		{name: "unsupported", defaultLanguage: i18n.Indonesian, acceptLanguage: "fr", want: i18n.Indonesian},
+		{name: "wildcard", defaultLanguage: i18n.Indonesian, acceptLanguage: "*", want: i18n.Indonesian},
+		{name: "malformed", defaultLanguage: i18n.English, acceptLanguage: "en;q=x;;", want: i18n.English},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := i18n.Init(i18n.Config{DefaultLanguage: tt.defaultLanguage}); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := i18n.Negotiate(tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q) = %q, want %q", tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitRejectsUnsupportedLanguage(t *testing.T) {
+	if err := i18n.Init(i18n.Config{DefaultLanguage: "fr"}); err == nil {
+		t.Error("Init() accepted an unsupported default language")
+	}
+}
+
+func TestError(t *testing.T) {
+	if err := i18n.Init(i18n.Config{DefaultLanguage: i18n.English}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		lang string
+		code string
+		want string
+	}{
+		{name: "translated", lang: i18n.Indonesian, code: "AUTH_INVALID_TOKEN", want: "Token tidak valid"},
+		{name: "english is the fallback", lang: i18n.English, code: "AUTH_INVALID_TOKEN", want: "fallback"},
+		{name: "unknown code", lang: i18n.Indonesian, code: "UNKNOWN", want: "fallback"},
+		{name: "unknown language", lang: "fr", code: "AUTH_INVALID_TOKEN", want: "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := i18n.Error(tt.lang, tt.code, "fallback"); got != tt.want {
+				t.Errorf("Error(%q, %q) = %q, want %q", tt.lang, tt.code, got, tt.want)
+			}
+		})
+	}
+}
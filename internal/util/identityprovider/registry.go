@@ -0,0 +1,22 @@
+package identityprovider
+
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(providers ...Provider) *Registry {
+	registry := &Registry{
+		providers: make(map[string]Provider, len(providers)),
+	}
+
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+
+	return registry
+}
+
+func (r *Registry) Get(name string) (Provider, bool) {
+	provider, ok := r.providers[name]
+	return provider, ok
+}
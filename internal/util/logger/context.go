@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey struct{}
+
+// WithValue returns a copy of ctx carrying key=value. Every log written with
+// one of the *Context functions and that ctx includes the value, which is how
+// request scoped data such as the request id reaches repository logs.
+func WithValue(ctx context.Context, key string, value string) context.Context {
+	current, _ := ctx.Value(contextKey{}).([]any)
+
+	values := make([]any, 0, len(current)+1)
+	values = append(values, current...)
+	values = append(values, slog.String(key, value))
+
+	return context.WithValue(ctx, contextKey{}, values)
+}
+
+// Value returns the value stored under key by WithValue, or "".
+func Value(ctx context.Context, key string) string {
+	values, _ := ctx.Value(contextKey{}).([]any)
+
+	for i := len(values) - 1; i >= 0; i-- {
+		if attr, ok := values[i].(slog.Attr); ok && attr.Key == key {
+			return attr.Value.String()
+		}
+	}
+
+	return ""
+}
+
+func InfoContext(ctx context.Context, msg string, process string, details interface{}) {
+	logContext(ctx, slog.LevelInfo, msg, process, details)
+}
+
+func DebugContext(ctx context.Context, msg string, process string, details interface{}) {
+	logContext(ctx, slog.LevelDebug, msg, process, details)
+}
+
+func WarnContext(ctx context.Context, msg string, process string, details interface{}) {
+	logContext(ctx, slog.LevelWarn, msg, process, details)
+}
+
+func ErrorContext(ctx context.Context, msg string, process string, details interface{}) {
+	logContext(ctx, slog.LevelError, msg, process, details)
+}
+
+// AuditContext writes an info entry that is kept whatever level SetLevel
+// set.
+func AuditContext(ctx context.Context, msg string, process string, details interface{}) {
+	args := []any{slog.String("process", process), slog.Any("details", details)}
+
+	auditLogger.Log(ctx, slog.LevelInfo, msg, append(args, contextArgs(ctx)...)...)
+}
+
+// AttrsContext writes msg with attrs as top-level fields instead of a details
+// map, for lines meant to be queried such as access logs.
+func AttrsContext(ctx context.Context, level slog.Level, msg string, attrs ...slog.Attr) {
+	args := make([]any, 0, len(attrs))
+	for _, attr := range attrs {
+		args = append(args, attr)
+	}
+
+	logger.Log(ctx, level, msg, append(args, contextArgs(ctx)...)...)
+}
+
+func logContext(ctx context.Context, level slog.Level, msg string, process string, details interface{}) {
+	args := []any{slog.String("process", process), slog.Any("details", details)}
+
+	logger.Log(ctx, level, msg, append(args, contextArgs(ctx)...)...)
+}
+
+// contextArgs returns the values stored with WithValue and the ids of the
+// span in ctx.
+func contextArgs(ctx context.Context) []any {
+	values, _ := ctx.Value(contextKey{}).([]any)
+
+	args := make([]any, 0, len(values)+2)
+	args = append(args, values...)
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		args = append(args,
+			slog.String("traceId", spanContext.TraceID().String()),
+			slog.String("spanId", spanContext.SpanID().String()),
+		)
+	}
+
+	return args
+}
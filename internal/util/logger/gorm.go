@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration above which queries are logged as warnings.
+const slowQueryThreshold = 200 * time.Millisecond
+
+// unboundPlaceholder matches the "$1$" GORM renders for a placeholder without
+// a value, which ParamsFilter causes.
+var unboundPlaceholder = regexp.MustCompile(`\$(\d+)\$`)
+
+// GormLogger writes GORM's logs through this package, so they share its sinks,
+// level and redaction. Queries are logged at debug, slow ones at warn and
+// failed ones at error. Bound values are never logged since there is no key
+// telling whether one is a password or a token; statements keep their $n
+// placeholders.
+type GormLogger struct {
+	level gormlogger.LogLevel
+}
+
+func NewGormLogger() *GormLogger {
+	return &GormLogger{level: gormlogger.Info}
+}
+
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &GormLogger{level: level}
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		AttrsContext(ctx, slog.LevelInfo, "gorm", slog.String("detail", fmt.Sprintf(msg, args...)))
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		AttrsContext(ctx, slog.LevelWarn, "gorm", slog.String("detail", fmt.Sprintf(msg, args...)))
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		AttrsContext(ctx, slog.LevelError, "gorm", slog.String("detail", fmt.Sprintf(msg, args...)))
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+
+	level := slog.LevelDebug
+	switch {
+	case err != nil && !errors.Is(err, gormlogger.ErrRecordNotFound):
+		level = slog.LevelError
+	case elapsed >= slowQueryThreshold:
+		level = slog.LevelWarn
+	}
+
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []slog.Attr{
+		slog.String("sql", unboundPlaceholder.ReplaceAllString(sql, "$$$1")),
+		slog.Int64("rows", rows),
+		slog.Float64("latencyMs", float64(elapsed.Microseconds())/1000),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+
+	AttrsContext(ctx, level, "sql", attrs...)
+}
+
+// ParamsFilter drops the bound values before GORM renders the statement.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
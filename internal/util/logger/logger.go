@@ -1,56 +1,153 @@
 package logger
 
 import (
+	"errors"
+	"io"
 	"log/slog"
 	"os"
-	"time"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
-var logger *slog.Logger
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+
+	OutputStdout = "stdout"
+	OutputFile   = "file"
+)
+
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// level is shared by every handler so that SetLevel applies at runtime.
+var level = new(slog.LevelVar)
+
+// redact masks sensitive values in every handler; Init replaces it with the
+// configured one.
+var redact = newRedactor(RedactionConfig{})
+
+// logger writes to stderr until Init runs, so early failures are not lost.
+var logger = slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+	Level:       level,
+	ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr { return redact.replaceAttr(groups, a) },
+}))
+
+// auditLogger shares the outputs of logger but always writes info, so that
+// raising the level with SetLevel never drops audit entries.
+var auditLogger = logger
+
+var fileSink *lumberjack.Logger
+
+type Config struct {
+	Level     string
+	Format    string
+	Outputs   []string
+	File      FileConfig
+	Redaction RedactionConfig
+}
+
+// FileConfig controls the file sink, which is rotated once it reaches
+// MaxSizeMB and whenever Rotate is called.
+type FileConfig struct {
+	Path      string
+	MaxSizeMB int
+	// MaxBackups is how many rotated files are kept; 0 keeps all of them.
+	MaxBackups int
+	// MaxAgeDays removes rotated files older than this; 0 keeps them.
+	MaxAgeDays int
+	Compress   bool
+}
+
+func Init(config Config) error {
+	if err := SetLevel(config.Level); err != nil {
+		return err
+	}
+
+	var writers []io.Writer
+	for _, output := range config.Outputs {
+		switch output {
+		case OutputStdout:
+			writers = append(writers, os.Stdout)
+		case OutputFile:
+			fileSink = &lumberjack.Logger{
+				Filename:   config.File.Path,
+				MaxSize:    config.File.MaxSizeMB,
+				MaxBackups: config.File.MaxBackups,
+				MaxAge:     config.File.MaxAgeDays,
+				Compress:   config.File.Compress,
+			}
+			writers = append(writers, fileSink)
+		default:
+			return errors.New("unknown log output " + output)
+		}
+	}
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
 
-func Init(logFilePath string) error {
-	logFile, err := os.OpenFile(logFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	redact = newRedactor(config.Redaction)
+
+	out := io.MultiWriter(writers...)
+
+	handler, err := newHandler(config.Format, out, level)
+	if err != nil {
+		return err
+	}
+	auditHandler, err := newHandler(config.Format, out, slog.LevelInfo)
 	if err != nil {
 		return err
 	}
 
-	logger = slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{
-		Level: slog.LevelDebug,
-	}))
+	logger = slog.New(handler)
+	auditLogger = slog.New(auditHandler)
 
 	slog.SetDefault(logger)
 
-	cleanupOldLogs()
-
 	return nil
 }
 
-func cleanupOldLogs() {
-	// Define the maximum age for logs (e.g., delete logs older than 7 days)
-	maxAge := 30 * 24 * time.Hour
+func newHandler(format string, out io.Writer, level slog.Leveler) (slog.Handler, error) {
+	options := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact.replaceAttr,
+	}
 
-	// Check if the log file exists
-	logFile := "app.log"
-	fileInfo, err := os.Stat(logFile)
-	if err != nil {
-		logger.Error("logger", "Error reading log file:", map[string]string{
-			"error": err.Error(),
-		})
-		return
+	switch format {
+	case FormatJSON:
+		return slog.NewJSONHandler(out, options), nil
+	case FormatText, "":
+		return slog.NewTextHandler(out, options), nil
+	default:
+		return nil, errors.New("unknown log format " + format)
 	}
+}
 
-	// Check if the file is older than the max age
-	if time.Since(fileInfo.ModTime()) > maxAge {
-		err := os.Remove(logFile)
-		if err != nil {
-			logger.Error("logger", "Error deleting old log file:", map[string]string{
-				"error": err.Error(),
-			})
-			return
-		} else {
-			logger.Info("logger", "Old log file deleted.", nil)
-		}
+// SetLevel changes the minimum level written, e.g. "debug" or "warn".
+func SetLevel(name string) error {
+	var parsed slog.Level
+	if err := parsed.UnmarshalText([]byte(name)); err != nil {
+		return ErrInvalidLevel
 	}
+
+	level.Set(parsed)
+
+	return nil
+}
+
+// Level returns the current minimum level in lower case.
+func Level() string {
+	return strings.ToLower(level.Level().String())
+}
+
+// Rotate starts a new log file, compressing and pruning old ones as
+// configured. It does nothing without a file sink.
+func Rotate() error {
+	if fileSink == nil {
+		return nil
+	}
+
+	return fileSink.Rotate()
 }
 
 func Info(msg string, process string, details interface{}) {
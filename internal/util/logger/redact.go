@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+)
+
+// RedactedValue replaces masked values.
+const RedactedValue = "[REDACTED]"
+
+// sensitiveKeys are always masked. Keys match when they contain one of these,
+// ignoring case, so "accessToken" and "DB_PASSWORD" are masked too.
+var sensitiveKeys = []string{"password", "token", "dsn", "secret", "authorization"}
+
+// piiKeys are hashed when RedactionConfig.HashPII is set.
+var piiKeys = []string{"username", "email", "phone"}
+
+type RedactionConfig struct {
+	// Keys are masked in addition to password, token, dsn, secret and
+	// authorization.
+	Keys []string
+	// HashPII replaces usernames, emails and phone numbers with a keyed hash,
+	// so lines about the same person can still be correlated.
+	HashPII bool
+	HashKey string
+}
+
+type redactor struct {
+	sensitive []string
+	hashPII   bool
+	hashKey   []byte
+}
+
+func newRedactor(config RedactionConfig) *redactor {
+	sensitive := append([]string{}, sensitiveKeys...)
+	for _, key := range config.Keys {
+		sensitive = append(sensitive, strings.ToLower(key))
+	}
+
+	return &redactor{
+		sensitive: sensitive,
+		hashPII:   config.HashPII,
+		hashKey:   []byte(config.HashKey),
+	}
+}
+
+// replaceAttr is the slog.HandlerOptions.ReplaceAttr hook. Besides top level
+// attributes it looks into the details maps passed to Info, Error and friends.
+func (r *redactor) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+		return a
+	}
+
+	a.Value = r.value(a.Key, a.Value)
+
+	return a
+}
+
+func (r *redactor) value(key string, value slog.Value) slog.Value {
+	if matches(key, r.sensitive) {
+		return slog.StringValue(RedactedValue)
+	}
+
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.StringValue(r.string(key, value.String()))
+	case slog.KindAny:
+		switch details := value.Any().(type) {
+		case map[string]string:
+			redacted := make(map[string]string, len(details))
+			for k, v := range details {
+				redacted[k] = r.string(k, v)
+			}
+			return slog.AnyValue(redacted)
+		case map[string]interface{}:
+			redacted := make(map[string]interface{}, len(details))
+			for k, v := range details {
+				redacted[k] = r.value(k, slog.AnyValue(v)).Any()
+			}
+			return slog.AnyValue(redacted)
+		}
+	}
+
+	return value
+}
+
+func (r *redactor) string(key string, value string) string {
+	if matches(key, r.sensitive) {
+		return RedactedValue
+	}
+	if r.hashPII && value != "" && matches(key, piiKeys) {
+		return r.hash(value)
+	}
+
+	return value
+}
+
+// hash returns a short HMAC-SHA256 of value. It is keyed so that common
+// usernames and emails cannot be recovered by hashing a dictionary.
+func (r *redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(strings.ToLower(value)))
+
+	return "hmac:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+func matches(key string, candidates []string) bool {
+	key = strings.ToLower(key)
+	for _, candidate := range candidates {
+		if strings.Contains(key, candidate) {
+			return true
+		}
+	}
+
+	return false
+}
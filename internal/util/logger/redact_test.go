@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRedactorKeys(t *testing.T) {
+	r := newRedactor(RedactionConfig{Keys: []string{"PIN"}})
+
+	tests := []struct {
+		key      string
+		redacted bool
+	}{
+		{key: "password", redacted: true},
+		{key: "DB_PASSWORD", redacted: true},
+		{key: "accessToken", redacted: true},
+		{key: "refresh_token", redacted: true},
+		{key: "dsn", redacted: true},
+		{key: "clientSecret", redacted: true},
+		{key: "Authorization", redacted: true},
+		{key: "pin", redacted: true},
+		{key: "cardPin", redacted: true},
+		{key: "body", redacted: false},
+		{key: "message", redacted: false},
+		{key: "detail", redacted: false},
+		{key: "sql", redacted: false},
+		{key: "username", redacted: false},
+		{key: "email", redacted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got := r.replaceAttr(nil, slog.String(tt.key, "value")).Value.String()
+			if redacted := got == RedactedValue; redacted != tt.redacted {
+				t.Errorf("replaceAttr(%q) = %q, redacted %v, want %v", tt.key, got, redacted, tt.redacted)
+			}
+		})
+	}
+}
+
+func TestRedactorDetails(t *testing.T) {
+	r := newRedactor(RedactionConfig{})
+
+	tests := []struct {
+		name    string
+		key     string
+		details any
+		want    any
+	}{
+		{
+			name:    "string map",
+			key:     "details",
+			details: map[string]string{"username": "alice", "password": "hunter2"},
+			want:    map[string]string{"username": "alice", "password": RedactedValue},
+		},
+		{
+			name:    "nested map",
+			key:     "details",
+			details: map[string]interface{}{"request": map[string]string{"token": "abc", "route": "/v1"}},
+			want:    map[string]interface{}{"request": map[string]string{"token": RedactedValue, "route": "/v1"}},
+		},
+		{
+			name:    "sensitive key masks the whole map",
+			key:     "secrets",
+			details: map[string]string{"a": "b"},
+			want:    RedactedValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.replaceAttr(nil, slog.Any(tt.key, tt.details)).Value.Any()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("replaceAttr(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorKeepsBuiltInAttributes(t *testing.T) {
+	r := newRedactor(RedactionConfig{Keys: []string{"msg", "level"}})
+
+	for _, key := range []string{slog.MessageKey, slog.LevelKey} {
+		if got := r.replaceAttr(nil, slog.String(key, "value")).Value.String(); got != "value" {
+			t.Errorf("replaceAttr(%q) = %q, want it kept", key, got)
+		}
+	}
+}
+
+func TestRedactorHashPII(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  RedactionConfig
+		key     string
+		value   string
+		want    string
+		hashed  bool
+		matches string
+	}{
+		{name: "disabled", config: RedactionConfig{}, key: "email", value: "a@kfc.id", want: "a@kfc.id"},
+		{name: "email", config: RedactionConfig{HashPII: true, HashKey: "k"}, key: "email", value: "a@kfc.id", hashed: true},
+		{name: "phone number", config: RedactionConfig{HashPII: true, HashKey: "k"}, key: "phoneNumber", value: "+628123", hashed: true},
+		{name: "case-insensitive value", config: RedactionConfig{HashPII: true, HashKey: "k"}, key: "userName", value: "ALICE", hashed: true, matches: "alice"},
+		{name: "empty value", config: RedactionConfig{HashPII: true, HashKey: "k"}, key: "email", value: "", want: ""},
+		{name: "other key", config: RedactionConfig{HashPII: true, HashKey: "k"}, key: "route", value: "/v1", want: "/v1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRedactor(tt.config)
+
+			got := r.replaceAttr(nil, slog.String(tt.key, tt.value)).Value.String()
+			if !tt.hashed {
+				if got != tt.want {
+					t.Errorf("replaceAttr(%q) = %q, want %q", tt.key, got, tt.want)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(got, "hmac:") || strings.Contains(got, tt.value) {
+				t.Errorf("replaceAttr(%q) = %q, want a hash", tt.key, got)
+			}
+			if tt.matches != "" {
+				if other := r.replaceAttr(nil, slog.String(tt.key, tt.matches)).Value.String(); other != got {
+					t.Errorf("hash of %q = %q, want %q as for %q", tt.matches, other, got, tt.value)
+				}
+			}
+		})
+	}
+}
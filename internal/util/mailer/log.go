@@ -0,0 +1,28 @@
+package mailer
+
+import "github.com/EputraP/kfc_be/internal/util/logger"
+
+type logMailer struct {
+	showBody bool
+}
+
+// NewLog returns a Mailer that only writes messages to the application log.
+// It is meant for local development. The body holds magic links, so it is
+// masked unless showBody is set; the recipient is hashed with LOG_HASH_PII.
+func NewLog(showBody bool) Mailer {
+	return &logMailer{showBody: showBody}
+}
+
+func (m logMailer) Send(to string, subject string, body string) error {
+	if !m.showBody {
+		body = logger.RedactedValue
+	}
+
+	logger.Info("logMailer Send", "Sending email", map[string]string{
+		"email":   to,
+		"subject": subject,
+		"body":    body,
+	})
+
+	return nil
+}
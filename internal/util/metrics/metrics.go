@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "kfc"
+
+const (
+	LoginMethodPassword  = "password"
+	LoginMethodMagicLink = "magic_link"
+	LoginMethodOTP       = "otp"
+	LoginMethodWebAuthn  = "webauthn"
+	LoginMethodOAuth     = "oauth"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency by method, route template and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "logins_total",
+		Help:      "Login attempts by method and result.",
+	}, []string{"method", "result"})
+
+	Registrations = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "registrations_total",
+		Help:      "Accounts registered through the register endpoint.",
+	})
+
+	Refreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "refreshes_total",
+		Help:      "Access token renewals by result.",
+	}, []string{"result"})
+
+	Lockouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "lockouts_total",
+		Help:      "Attempts rejected because too many failures were recorded, by method.",
+	}, []string{"method"})
+
+	TokenValidationFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "auth",
+		Name:      "token_validation_failures_total",
+		Help:      "Requests rejected by the auth middleware, by reason.",
+	}, []string{"reason"})
+)
+
+// ObserveLogin counts a login attempt as failed when err is not nil.
+func ObserveLogin(method string, err error) {
+	Logins.WithLabelValues(method, result(err)).Inc()
+}
+
+// ObserveRefresh counts an access token renewal as failed when err is not nil.
+func ObserveRefresh(err error) {
+	Refreshes.WithLabelValues(result(err)).Inc()
+}
+
+// RegisterDBStats exports the connection pool statistics of db.
+func RegisterDBStats(db *sql.DB, dbName string) error {
+	return prometheus.Register(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// Handler serves every registered metric in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+func result(err error) string {
+	if err != nil {
+		return resultFailure
+	}
+	return resultSuccess
+}
@@ -0,0 +1,39 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Check compares the document with the routes registered on a gin engine and
+// returns an error listing every route without an operation and every
+// operation without a route.
+func Check(d *Document, routes gin.RoutesInfo) error {
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[routeKey(route.Method, route.Path)] = true
+	}
+
+	var problems []string
+	for key := range registered {
+		if _, ok := d.operations[key]; !ok {
+			problems = append(problems, "undocumented route "+key)
+		}
+	}
+	for key := range d.operations {
+		if !registered[key] {
+			problems = append(problems, "documented route is not registered "+key)
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	sort.Strings(problems)
+
+	return fmt.Errorf("openapi document does not match the routes:\n  %s", strings.Join(problems, "\n  "))
+}
@@ -0,0 +1,9 @@
+window.onload = function () {
+  var root = document.getElementById("swagger-ui");
+
+  window.ui = SwaggerUIBundle({
+    url: root.dataset.specUrl,
+    dom_id: "#swagger-ui",
+    withCredentials: true,
+  });
+};
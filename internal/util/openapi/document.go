@@ -0,0 +1,380 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+)
+
+const (
+	Version = "3.1.0"
+
+	ContentTypeJSON = "application/json"
+
+	// Security schemes of Document, referenced by Operation.Security.
+	SecurityBearer = "bearerAuth"
+	SecurityCookie = "cookieAuth"
+	SecuritySCIM   = "scimAuth"
+)
+
+// Document is an OpenAPI 3.1 document. Paths are added with Add and the
+// schemas of the types they use are collected in Components.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+
+	operations map[string]Operation
+	types      map[reflect.Type]string
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+// PathItem maps lower case HTTP methods to their operation.
+type PathItem map[string]*OperationObject
+
+type OperationObject struct {
+	OperationID string                    `json:"operationId"`
+	Summary     string                    `json:"summary"`
+	Description string                    `json:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Deprecated  bool                      `json:"deprecated,omitempty"`
+	Security    []map[string][]string     `json:"security,omitempty"`
+	Parameters  []ParameterObject         `json:"parameters,omitempty"`
+	RequestBody *RequestBodyObject        `json:"requestBody,omitempty"`
+	Responses   map[string]ResponseObject `json:"responses"`
+}
+
+type ParameterObject struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBodyObject struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type ResponseObject struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Parameter is a query parameter of an Operation. Path parameters are taken
+// from the path.
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+}
+
+// Operation describes one route. The zero values describe the common case:
+// a JSON body and a 200 response in the envelope of response.JSON.
+type Operation struct {
+	Method string
+	// Path is the gin path, e.g. "/admin/users/:id/impersonate".
+	Path        string
+	Tag         string
+	Summary     string
+	Description string
+	Deprecated  bool
+	// Security lists the schemes the route accepts, any one of them is
+	// enough. Empty for public routes.
+	Security []string
+	Query    []Parameter
+
+	// RequestBody is a value of the body type, nil for routes without one.
+	RequestBody interface{}
+	// RequestType is the content type of the body, application/json when
+	// empty.
+	RequestType string
+	// OptionalBody is set when the body may be left out.
+	OptionalBody bool
+
+	// Status is the status of a successful response, 200 when zero.
+	Status int
+	// Response is a value of the type of the response data, or a *Schema.
+	Response interface{}
+	// Raw is set when Response is written as is instead of in the envelope,
+	// with ResponseType as content type. A Raw operation with a nil Response
+	// has no body.
+	Raw          bool
+	ResponseType string
+
+	// Errors are the errors the route fails with, documented by status.
+	Errors []*errs.AppError
+	// ErrorResponse replaces the envelope and problem details of error
+	// responses, with ErrorType as content type.
+	ErrorResponse interface{}
+	ErrorType     string
+}
+
+// New returns a Document with the security schemes, the response envelope
+// and the problem details the operations refer to.
+func New(info Info, tags ...Tag) *Document {
+	d := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Tags:    tags,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: map[string]*Schema{},
+			SecuritySchemes: map[string]SecurityScheme{
+				SecurityBearer: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				SecurityCookie: {Type: "apiKey", In: "cookie", Name: constant.CookieAccessToken, Description: "Set by the login routes when cookie auth is enabled."},
+				SecuritySCIM:   {Type: "http", Scheme: "bearer", Description: "The static SCIM bearer token."},
+			},
+		},
+		operations: map[string]Operation{},
+		types:      map[reflect.Type]string{},
+	}
+
+	d.Components.Schemas[envelopeSchema] = &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":      {Type: "integer", Description: "HTTP status of the response."},
+			"msg":       {Type: "string", Description: "Message in the negotiated language."},
+			"data":      {},
+			"errorCode": {Type: "string", Description: "Stable code of an error response."},
+			"requestId": {Type: "string"},
+		},
+		Required: []string{"code", "msg", "data"},
+	}
+	d.Components.Schemas[problemSchema] = &Schema{
+		Type:        "object",
+		Description: "RFC 7807 problem details, served when ERROR_FORMAT=problem or when requested with Accept.",
+		Properties: map[string]*Schema{
+			"type":      {Type: "string"},
+			"title":     {Type: "string"},
+			"status":    {Type: "integer"},
+			"detail":    {Type: "string"},
+			"instance":  {Type: "string"},
+			"code":      {Type: "string"},
+			"requestId": {Type: "string"},
+			"details":   {},
+		},
+		Required: []string{"type", "title", "status", "code"},
+	}
+
+	return d
+}
+
+// Add documents op. It panics when the route is documented twice, which is
+// a programming error.
+func (d *Document) Add(op Operation) {
+	key := routeKey(op.Method, op.Path)
+	if _, ok := d.operations[key]; ok {
+		panic("openapi: duplicate operation " + key)
+	}
+	d.operations[key] = op
+
+	path, pathParameters := openAPIPath(op.Path)
+
+	object := &OperationObject{
+		OperationID: operationID(op.Method, op.Path),
+		Summary:     op.Summary,
+		Description: op.Description,
+		Deprecated:  op.Deprecated,
+		Responses:   map[string]ResponseObject{},
+	}
+	if op.Tag != "" {
+		object.Tags = []string{op.Tag}
+	}
+
+	for _, scheme := range op.Security {
+		object.Security = append(object.Security, map[string][]string{scheme: {}})
+	}
+
+	for _, name := range pathParameters {
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	for _, parameter := range op.Query {
+		object.Parameters = append(object.Parameters, ParameterObject{
+			Name:        parameter.Name,
+			In:          "query",
+			Description: parameter.Description,
+			Required:    parameter.Required,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	if op.RequestBody != nil {
+		object.RequestBody = &RequestBodyObject{
+			Required: !op.OptionalBody,
+			Content: map[string]MediaType{
+				orDefault(op.RequestType, ContentTypeJSON): {Schema: d.Schema(op.RequestBody)},
+			},
+		}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	object.Responses[strconv.Itoa(status)] = d.successResponse(op, status)
+
+	d.addErrorResponses(object, op)
+
+	item, ok := d.Paths[path]
+	if !ok {
+		item = PathItem{}
+		d.Paths[path] = item
+	}
+	item[strings.ToLower(op.Method)] = object
+}
+
+func (d *Document) successResponse(op Operation, status int) ResponseObject {
+	description := http.StatusText(status)
+
+	if op.Raw {
+		if op.Response == nil {
+			return ResponseObject{Description: description}
+		}
+
+		return ResponseObject{
+			Description: description,
+			Content: map[string]MediaType{
+				orDefault(op.ResponseType, ContentTypeJSON): {Schema: d.Schema(op.Response)},
+			},
+		}
+	}
+
+	schema := ref(envelopeSchema)
+	if op.Response != nil {
+		schema = &Schema{AllOf: []*Schema{
+			ref(envelopeSchema),
+			{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": d.Schema(op.Response)},
+			},
+		}}
+	}
+
+	return ResponseObject{
+		Description: description,
+		Content:     map[string]MediaType{ContentTypeJSON: {Schema: schema}},
+	}
+}
+
+// addErrorResponses documents op.Errors grouped by status, listing their
+// codes, and a 500 for every operation.
+func (d *Document) addErrorResponses(object *OperationObject, op Operation) {
+	codes := map[int][]string{}
+	for _, err := range append(op.Errors, errs.Internal) {
+		codes[err.Status] = append(codes[err.Status], fmt.Sprintf("`%s`: %s", err.Code, err.Message))
+	}
+
+	content := map[string]MediaType{
+		ContentTypeJSON:            {Schema: ref(envelopeSchema)},
+		"application/problem+json": {Schema: ref(problemSchema)},
+	}
+	if op.ErrorResponse != nil {
+		content = map[string]MediaType{
+			orDefault(op.ErrorType, ContentTypeJSON): {Schema: d.Schema(op.ErrorResponse)},
+		}
+	}
+
+	for status, list := range codes {
+		sort.Strings(list)
+		object.Responses[strconv.Itoa(status)] = ResponseObject{
+			Description: strings.Join(list, "\n\n"),
+			Content:     content,
+		}
+	}
+}
+
+// Operations returns the method and gin path of every documented route.
+func (d *Document) Operations() []string {
+	keys := make([]string, 0, len(d.operations))
+	for key := range d.operations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func routeKey(method string, path string) string {
+	return method + " " + path
+}
+
+// openAPIPath turns the gin parameters of path into OpenAPI ones, e.g.
+// "/users/:id" into "/users/{id}", and returns their names.
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+
+	var parameters []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			parameters = append(parameters, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
+
+// operationID derives a stable id from the route, e.g. "post_auth_register".
+func operationID(method string, path string) string {
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, ":*")
+		segment = strings.NewReplacer("-", "_", ".", "_").Replace(segment)
+		if segment != "" {
+			id += "_" + segment
+		}
+	}
+
+	return id
+}
+
+func orDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,81 @@
+package openapi
+
+import (
+	_ "embed"
+	"html/template"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerfiles "github.com/swaggo/files/v2"
+)
+
+//go:embed docs.html
+var docsPage string
+
+//go:embed docs.js
+var docsScript []byte
+
+var docsTemplate = template.Must(template.New("docs").Parse(docsPage))
+
+// docsPolicy only lets the docs page load the assets served by
+// DocsAssetHandler and call the API it documents.
+const docsPolicy = "default-src 'none'; script-src 'self'; style-src 'self' 'unsafe-inline'; " +
+	"img-src 'self' data:; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// docsAssets are the files DocsAssetHandler serves, by name. Swagger UI is
+// embedded in the binary, so the docs do not depend on a CDN.
+var docsAssets = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+	"docs.js":              "text/javascript; charset=utf-8",
+}
+
+// Handler serves the document as JSON.
+func Handler(d *Document) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, d)
+	}
+}
+
+// DocsHandler serves a Swagger UI page rendering the document at specURL.
+// The page loads its assets from assetsURL, served by DocsAssetHandler.
+func DocsHandler(title string, specURL string, assetsURL string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Header("Content-Type", "text/html; charset=utf-8")
+		ctx.Header("Content-Security-Policy", docsPolicy)
+		ctx.Status(http.StatusOK)
+
+		_ = docsTemplate.Execute(ctx.Writer, map[string]string{
+			"Title":     title,
+			"SpecURL":   specURL,
+			"AssetsURL": assetsURL,
+		})
+	}
+}
+
+// DocsAssetHandler serves the Swagger UI file named by the asset parameter.
+func DocsAssetHandler() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		name := ctx.Param("asset")
+
+		contentType, ok := docsAssets[name]
+		if !ok {
+			ctx.Status(http.StatusNotFound)
+			return
+		}
+
+		data := docsScript
+		if name != "docs.js" {
+			var err error
+			data, err = fs.ReadFile(swaggerfiles.FS, name)
+			if err != nil {
+				ctx.Status(http.StatusNotFound)
+				return
+			}
+		}
+
+		ctx.Header("Cache-Control", "public, max-age=86400")
+		ctx.Data(http.StatusOK, contentType, data)
+	}
+}
@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/google/uuid"
+)
+
+const (
+	envelopeSchema = "Response"
+	problemSchema  = "Problem"
+)
+
+// Schema is the subset of JSON Schema the document uses.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	uuidType       = reflect.TypeOf(uuid.UUID{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Object returns a schema for data whose shape is defined elsewhere, e.g.
+// the WebAuthn options passed as is to the browser API.
+func Object(description string) *Schema {
+	return &Schema{Type: "object", Description: description}
+}
+
+// Schema returns the schema of the type of v, or v itself when it is a
+// *Schema. Structs are added to the components and referenced.
+func (d *Document) Schema(v interface{}) *Schema {
+	if schema, ok := v.(*Schema); ok {
+		return schema
+	}
+
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	case rawMessageType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return d.structRef(t)
+	default:
+		// interface{} holds any JSON value
+		return &Schema{}
+	}
+}
+
+// structRef adds the schema of t to the components under its type name,
+// prefixed with the package name when another type has it, and returns a
+// reference to it. Anonymous structs are inlined.
+func (d *Document) structRef(t reflect.Type) *Schema {
+	if name, ok := d.types[t]; ok {
+		return ref(name)
+	}
+
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if t.Name() == "" {
+		d.addFields(schema, t)
+		return schema
+	}
+
+	name := t.Name()
+	if _, taken := d.Components.Schemas[name]; taken {
+		name = packageName(t) + name
+	}
+
+	// Registered before the fields so recursive types terminate
+	d.types[t] = name
+	d.Components.Schemas[name] = schema
+	d.addFields(schema, t)
+
+	return ref(name)
+}
+
+func (d *Document) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				d.addFields(schema, embedded)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := d.schemaOf(field.Type)
+		if property.Ref == "" {
+			if required := applyRules(property, field.Tag.Get("binding")); required {
+				schema.Required = append(schema.Required, name)
+			}
+		} else if strings.Contains(field.Tag.Get("binding"), "required") {
+			schema.Required = append(schema.Required, name)
+		}
+
+		schema.Properties[name] = property
+	}
+}
+
+// applyRules documents the validator rules of a binding tag on property and
+// reports whether the field is required.
+func applyRules(property *Schema, binding string) bool {
+	required := false
+
+	for _, rule := range strings.Split(binding, ",") {
+		tag, param, _ := strings.Cut(rule, "=")
+
+		switch tag {
+		case "required":
+			required = true
+		case "email":
+			property.Format = "email"
+		case "uuid":
+			property.Format = "uuid"
+		case "numeric":
+			property.Pattern = "^[0-9]+$"
+		case "min", "max":
+			length, err := strconv.Atoi(param)
+			if err != nil || property.Type != "string" {
+				continue
+			}
+			if tag == "min" {
+				property.MinLength = &length
+			} else {
+				property.MaxLength = &length
+			}
+		default:
+			if pattern, ok := validation.Pattern(tag); ok {
+				property.Pattern = pattern
+			}
+		}
+	}
+
+	return required
+}
+
+func packageName(t reflect.Type) string {
+	path := t.PkgPath()
+	name := path[strings.LastIndex(path, "/")+1:]
+
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
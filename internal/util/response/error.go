@@ -0,0 +1,72 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/gin-gonic/gin"
+)
+
+const ContentTypeProblem = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Code, RequestID and
+// Details are extension members.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"requestId,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Fail records err for middleware.ErrorHandler, which writes the response,
+// and stops the handler chain.
+func Fail(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+}
+
+// WriteError writes err in the JSON envelope, or as problem details when
+// problem is set, in the language of the request. The envelope carries the
+// code in errorCode and the details in data.
+func WriteError(ctx *gin.Context, err *errs.AppError, problem bool) {
+	requestId := logger.Value(ctx.Request.Context(), constant.LogKeyRequestID)
+
+	lang := i18n.FromContext(ctx.Request.Context())
+	message := i18n.Error(lang, err.Code, err.Message)
+
+	details := err.Details
+	if translatable, ok := details.(i18n.Translatable); ok {
+		details = translatable.Translate(lang)
+	}
+
+	if !problem {
+		resp := NewResponse(err.Status, message, details)
+		resp.ErrorCode = err.Code
+		resp.RequestID = requestId
+
+		ctx.JSON(err.Status, resp)
+		return
+	}
+
+	body := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(err.Status),
+		Status:    err.Status,
+		Detail:    message,
+		Instance:  ctx.Request.URL.Path,
+		Code:      err.Code,
+		RequestID: requestId,
+		Details:   details,
+	}
+
+	// gin keeps a Content-Type that is already set
+	ctx.Header("Content-Type", ContentTypeProblem)
+	ctx.JSON(err.Status, body)
+}
@@ -4,6 +4,10 @@ type Response struct {
 	Code    int         `json:"code"`
 	Message string      `json:"msg"`
 	Data    interface{} `json:"data"`
+	// ErrorCode is the errs.AppError code of error responses.
+	ErrorCode string `json:"errorCode,omitempty"`
+	// RequestID echoes the X-Request-ID of the request, for support tickets.
+	RequestID string `json:"requestId,omitempty"`
 }
 
 func NewResponse(code int, message string, data interface{}) *Response {
@@ -0,0 +1,90 @@
+package scim
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidFilter = errors.New("invalid or unsupported filter")
+
+// Filter is a single attribute comparison such as `userName eq "alice"`.
+// Logical operators and grouping are not supported.
+type Filter struct {
+	Attribute string
+	Operator  string
+	Value     string
+}
+
+var filterOperators = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true, "pr": true,
+}
+
+func ParseFilter(filter string) (*Filter, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(filter, " ", 3)
+	if len(parts) < 2 {
+		return nil, ErrInvalidFilter
+	}
+
+	parsed := &Filter{
+		Attribute: parts[0],
+		Operator:  strings.ToLower(parts[1]),
+	}
+
+	if !filterOperators[parsed.Operator] {
+		return nil, ErrInvalidFilter
+	}
+
+	if parsed.Operator == "pr" {
+		if len(parts) != 2 {
+			return nil, ErrInvalidFilter
+		}
+		return parsed, nil
+	}
+
+	if len(parts) != 3 {
+		return nil, ErrInvalidFilter
+	}
+
+	value := strings.TrimSpace(parts[2])
+	if strings.HasPrefix(value, `"`) {
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			return nil, ErrInvalidFilter
+		}
+		value = strings.ReplaceAll(value[1:len(value)-1], `\"`, `"`)
+	}
+
+	parsed.Value = value
+
+	return parsed, nil
+}
+
+// AttributeName strips value filters and sub-attributes from a filter or
+// PATCH path, e.g. `emails[type eq "work"].value` becomes "emails".
+func AttributeName(path string) string {
+	if i := strings.IndexAny(path, "[."); i >= 0 {
+		path = path[:i]
+	}
+
+	return strings.ToLower(path)
+}
+
+// ValueFilter returns the value of an `attr[value eq "x"]` path.
+func ValueFilter(path string) (string, bool) {
+	start := strings.Index(path, "[")
+	end := strings.LastIndex(path, "]")
+	if start < 0 || end < start {
+		return "", false
+	}
+
+	filter, err := ParseFilter(path[start+1 : end])
+	if err != nil || filter == nil || filter.Operator != "eq" {
+		return "", false
+	}
+
+	return filter.Value, true
+}
@@ -0,0 +1,95 @@
+package scim_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/EputraP/kfc_be/internal/util/scim"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  string
+		want    *scim.Filter
+		wantErr bool
+	}{
+		{name: "empty", filter: "", want: nil},
+		{name: "blank", filter: "   ", want: nil},
+		{name: "quoted value", filter: `userName eq "alice"`, want: &scim.Filter{Attribute: "userName", Operator: "eq", Value: "alice"}},
+		{name: "operator is case-insensitive", filter: `userName EQ "alice"`, want: &scim.Filter{Attribute: "userName", Operator: "eq", Value: "alice"}},
+		{name: "value with spaces", filter: `displayName eq "Store Managers"`, want: &scim.Filter{Attribute: "displayName", Operator: "eq", Value: "Store Managers"}},
+		{name: "escaped quote", filter: `userName eq "a\"b"`, want: &scim.Filter{Attribute: "userName", Operator: "eq", Value: `a"b`}},
+		{name: "unquoted value", filter: "active eq true", want: &scim.Filter{Attribute: "active", Operator: "eq", Value: "true"}},
+		{name: "present", filter: "externalId pr", want: &scim.Filter{Attribute: "externalId", Operator: "pr"}},
+		{name: "present with value", filter: `externalId pr "x"`, wantErr: true},
+		{name: "missing operator", filter: "userName", wantErr: true},
+		{name: "missing value", filter: "userName eq", wantErr: true},
+		{name: "unsupported operator", filter: `userName gt "a"`, wantErr: true},
+		{name: "unterminated quote", filter: `userName eq "alice`, wantErr: true},
+		{name: "lone quote", filter: `userName eq "`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scim.ParseFilter(tt.filter)
+			if tt.wantErr {
+				if !errors.Is(err, scim.ErrInvalidFilter) {
+					t.Fatalf("ParseFilter(%q) error = %v, want %v", tt.filter, err, scim.ErrInvalidFilter)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error = %v", tt.filter, err)
+			}
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("ParseFilter(%q) = %+v, want %+v", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAttributeName(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "userName", want: "username"},
+		{path: "name.givenName", want: "name"},
+		{path: `emails[type eq "work"].value`, want: "emails"},
+		{path: `members[value eq "1"]`, want: "members"},
+		{path: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := scim.AttributeName(tt.path); got != tt.want {
+				t.Errorf("AttributeName(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueFilter(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{path: `members[value eq "2819c223"]`, want: "2819c223", wantOk: true},
+		{path: `members[value EQ "2819c223"]`, want: "2819c223", wantOk: true},
+		{path: "members", wantOk: false},
+		{path: `members[value ne "2819c223"]`, wantOk: false},
+		{path: "members[]", wantOk: false},
+		{path: `members[value eq "2819c223"`, wantOk: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := scim.ValueFilter(tt.path)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ValueFilter(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
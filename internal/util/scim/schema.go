@@ -0,0 +1,87 @@
+package scim
+
+import "time"
+
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	ContentType = "application/scim+json"
+)
+
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+type MultiValue struct {
+	Value     string `json:"value"`
+	Display   string `json:"display,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Primary   bool   `json:"primary,omitempty"`
+	Formatted string `json:"formatted,omitempty"`
+}
+
+type User struct {
+	Schemas      []string     `json:"schemas"`
+	Id           string       `json:"id,omitempty"`
+	ExternalId   string       `json:"externalId,omitempty"`
+	UserName     string       `json:"userName" binding:"required"`
+	Active       *bool        `json:"active,omitempty"`
+	Emails       []MultiValue `json:"emails,omitempty"`
+	PhoneNumbers []MultiValue `json:"phoneNumbers,omitempty"`
+	Addresses    []MultiValue `json:"addresses,omitempty"`
+	Groups       []MultiValue `json:"groups,omitempty"`
+	Password     string       `json:"password,omitempty"`
+	Meta         *Meta        `json:"meta,omitempty"`
+}
+
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	Id          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName" binding:"required"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+type ListResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int64       `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations" binding:"required"`
+}
+
+// PatchOperation keeps Value raw because identity providers send booleans
+// both as JSON booleans and as strings, and objects when Path is empty.
+type PatchOperation struct {
+	Op    string      `json:"op" binding:"required"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// PrimaryValue returns the primary entry of a multi-valued attribute, or the
+// first one when none is marked primary.
+func PrimaryValue(values []MultiValue) string {
+	for _, value := range values {
+		if value.Primary {
+			return value.Value
+		}
+	}
+
+	if len(values) > 0 {
+		return values[0].Value
+	}
+
+	return ""
+}
@@ -0,0 +1,35 @@
+package scim
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func JSON(ctx *gin.Context, statusCode int, data interface{}) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		ErrorResponse(ctx, 500, "", err.Error())
+		return
+	}
+
+	ctx.Data(statusCode, ContentType, body)
+}
+
+func ErrorResponse(ctx *gin.Context, statusCode int, scimType string, detail string) {
+	JSON(ctx, statusCode, Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(statusCode),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+	ctx.Abort()
+}
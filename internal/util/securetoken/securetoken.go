@@ -0,0 +1,48 @@
+package securetoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+)
+
+// Generate returns size random bytes encoded as unpadded base64url.
+func Generate(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// GenerateNumeric returns a random decimal code with the given number of
+// digits, keeping leading zeros.
+func GenerateNumeric(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// Equal compares two signed values in constant time.
+func Equal(a string, b string) bool {
+	return hmac.Equal([]byte(a), []byte(b))
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of value. It is used to store
+// single-use secrets so that a leaked table cannot be replayed.
+func Sign(secret string, value string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(value))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
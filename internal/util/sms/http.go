@@ -0,0 +1,59 @@
+package sms
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type httpSender struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type httpSendBody struct {
+	To      string `json:"to"`
+	Message string `json:"message"`
+}
+
+// NewHTTP returns an SMSSender that POSTs {"to", "message"} as JSON to url.
+// It works with any gateway exposing that contract, including a local mock.
+func NewHTTP(url string, apiKey string) SMSSender {
+	return &httpSender{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s httpSender) Send(phoneNumber string, message string) error {
+	body, err := json.Marshal(httpSendBody{To: phoneNumber, Message: message})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
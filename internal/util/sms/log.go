@@ -0,0 +1,27 @@
+package sms
+
+import "github.com/EputraP/kfc_be/internal/util/logger"
+
+type logSender struct {
+	showMessage bool
+}
+
+// NewLog returns an SMSSender that only writes messages to the application
+// log. It is meant for local development. The message holds OTP codes, so it
+// is masked unless showMessage is set.
+func NewLog(showMessage bool) SMSSender {
+	return &logSender{showMessage: showMessage}
+}
+
+func (s logSender) Send(phoneNumber string, message string) error {
+	if !s.showMessage {
+		message = logger.RedactedValue
+	}
+
+	logger.Info("logSender Send", "Sending SMS", map[string]string{
+		"phoneNumber": phoneNumber,
+		"message":     message,
+	})
+
+	return nil
+}
@@ -0,0 +1,5 @@
+package sms
+
+type SMSSender interface {
+	Send(phoneNumber string, message string) error
+}
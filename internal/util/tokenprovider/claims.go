@@ -1,14 +1,46 @@
 package tokenprovider
 
 import (
+	"strings"
+
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// ActorClaims identifies the admin acting on behalf of the subject of an
+// impersonation token (RFC 8693 "act" claim).
+type ActorClaims struct {
+	UserID   string `json:"sub"`
+	Username string `json:"username"`
+}
+
 type UserClaims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
+	// OrganizationID is the active organization, OrganizationIDs every
+	// organization the user belonged to when the token was issued.
+	OrganizationID  string   `json:"org_id,omitempty"`
+	OrganizationIDs []string `json:"org_ids,omitempty"`
+	// Actor is only set on impersonation tokens.
+	Actor *ActorClaims `json:"act,omitempty"`
+	// Scope is a space separated list restricting impersonation tokens.
+	Scope string `json:"scope,omitempty"`
 }
+
 type JwtClaims struct {
 	jwt.RegisteredClaims
 	UserClaims
 }
+
+func (c UserClaims) IsImpersonated() bool {
+	return c.Actor != nil
+}
+
+func (c UserClaims) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scope) {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
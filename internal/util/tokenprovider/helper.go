@@ -1,21 +1,15 @@
 package tokenprovider
 
 import (
-	"os"
-	"strconv"
+	"time"
 
-	"github.com/EputraP/kfc_be/internal/constant"
+	"github.com/EputraP/kfc_be/internal/config"
 )
 
-func GetProvider() JWTTokenProvider {
-	issuer := constant.Issuer
-	secret := os.Getenv(constant.EnvKeyJWTSecret)
-	refreshTokenDurationString := os.Getenv(constant.EnvKeyRefreshTokenDuration)
-	accessTokenDurationString := os.Getenv(constant.EnvKeyAccessTokenDuration)
+func GetProvider(config config.JWT) JWTTokenProvider {
+	refreshTokenDuration := int(config.RefreshTokenDuration / time.Minute)
+	accessTokenDuration := int(config.AccessTokenDuration / time.Minute)
 
-	refreshTokenDuration, _ := strconv.Atoi(refreshTokenDurationString)
-	accessTokenDuration, _ := strconv.Atoi(accessTokenDurationString)
-
-	jwtProvider := NewJWT(issuer, secret, refreshTokenDuration, accessTokenDuration)
+	jwtProvider := NewJWT(config.Issuer, config.Secret, refreshTokenDuration, accessTokenDuration)
 	return jwtProvider
 }
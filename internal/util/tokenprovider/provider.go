@@ -14,9 +14,10 @@ import (
 type JWTTokenProvider interface {
 	GenerateRefreshToken(user model.User) (string, error)
 	GenerateAccessToken(user model.User) (string, error)
+	GenerateImpersonationToken(user model.User, actor model.User, scopes []string, expiresIn time.Duration) (string, error)
 	ValidateToken(token string) (*JwtClaims, error)
 	ExtractToken(authHeader string) (string, error)
-	RenewAccessToken(refreshTokenString string) (*string, error)
+	ParseRefreshToken(refreshTokenString string) (*model.User, error)
 }
 
 type jwtTokenProvider struct {
@@ -43,7 +44,24 @@ func (p *jwtTokenProvider) GenerateRefreshToken(user model.User) (string, error)
 	return p.generateToken(user, time.Duration(p.refreshTokenDuration)*time.Minute)
 }
 
+// GenerateImpersonationToken issues an access token for user carrying actor
+// in the "act" claim. It is never paired with a refresh token.
+func (p *jwtTokenProvider) GenerateImpersonationToken(user model.User, actor model.User, scopes []string, expiresIn time.Duration) (string, error) {
+	claims := p.newClaims(user, expiresIn)
+	claims.Actor = &ActorClaims{
+		UserID:   actor.Id.String(),
+		Username: actor.Username,
+	}
+	claims.Scope = strings.Join(scopes, " ")
+
+	return p.signClaims(claims)
+}
+
 func (p *jwtTokenProvider) generateToken(user model.User, expiresIn time.Duration) (string, error) {
+	return p.signClaims(p.newClaims(user, expiresIn))
+}
+
+func (p *jwtTokenProvider) newClaims(user model.User, expiresIn time.Duration) JwtClaims {
 	claims := JwtClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Issuer:    p.issuer,
@@ -56,6 +74,17 @@ func (p *jwtTokenProvider) generateToken(user model.User, expiresIn time.Duratio
 		},
 	}
 
+	if user.OrganizationId != uuid.Nil {
+		claims.OrganizationID = user.OrganizationId.String()
+	}
+	for _, organizationId := range user.OrganizationIds {
+		claims.OrganizationIDs = append(claims.OrganizationIDs, organizationId.String())
+	}
+
+	return claims
+}
+
+func (p *jwtTokenProvider) signClaims(claims JwtClaims) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	tokenStr, err := token.SignedString([]byte(p.secret))
 	if err != nil {
@@ -66,7 +95,10 @@ func (p *jwtTokenProvider) generateToken(user model.User, expiresIn time.Duratio
 	return tokenStr, nil
 }
 
-func (p *jwtTokenProvider) RenewAccessToken(refreshTokenString string) (*string, error) {
+// ParseRefreshToken verifies a refresh token and returns its user with the
+// active organization chosen when it was issued. Memberships are left to the
+// caller, which reads the current ones.
+func (p *jwtTokenProvider) ParseRefreshToken(refreshTokenString string) (*model.User, error) {
 	// Parse and verify the refresh token
 	refreshToken, err := jwt.Parse(refreshTokenString, func(t *jwt.Token) (interface{}, error) {
 		return []byte(p.secret), nil
@@ -76,8 +108,12 @@ func (p *jwtTokenProvider) RenewAccessToken(refreshTokenString string) (*string,
 		return nil, errs.InvalidToken
 	}
 
-	// Generate a new access token if refresh token is valid
 	if claims, ok := refreshToken.Claims.(jwt.MapClaims); ok && refreshToken.Valid {
+		// Impersonation tokens cannot be renewed
+		if _, impersonated := claims["act"]; impersonated {
+			return nil, errs.InvalidToken
+		}
+
 		username := claims["username"].(string)
 		userId := claims["user_id"].(string)
 
@@ -86,12 +122,14 @@ func (p *jwtTokenProvider) RenewAccessToken(refreshTokenString string) (*string,
 			return nil, errs.ParseUUIDError
 		}
 
-		newAccessTokenString, err := p.GenerateAccessToken(model.User{Username: username, Id: parsedUUID})
-		if err != nil {
-			return nil, err
+		user := model.User{Username: username, Id: parsedUUID}
+
+		// Keep the active organization chosen when the refresh token was issued
+		if organizationId, ok := claims["org_id"].(string); ok {
+			user.OrganizationId, _ = uuid.Parse(organizationId)
 		}
 
-		return &newAccessTokenString, nil
+		return &user, nil
 	} else {
 		return nil, errs.InvalidToken
 	}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// GormPlugin creates a span for every query run with a context, named after
+// the operation and carrying the SQL statement.
+type GormPlugin struct{}
+
+func (GormPlugin) Name() string {
+	return "tracing"
+}
+
+func (p GormPlugin) Initialize(db *gorm.DB) error {
+	return errors.Join(
+		db.Callback().Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		db.Callback().Create().After("gorm:create").Register("tracing:after_create", p.after),
+		db.Callback().Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		db.Callback().Query().After("gorm:query").Register("tracing:after_query", p.after),
+		db.Callback().Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		db.Callback().Update().After("gorm:update").Register("tracing:after_update", p.after),
+		db.Callback().Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		db.Callback().Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		db.Callback().Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		db.Callback().Row().After("gorm:row").Register("tracing:after_row", p.after),
+		db.Callback().Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		db.Callback().Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	)
+}
+
+func (GormPlugin) before(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Statement.Context == nil {
+			return
+		}
+
+		ctx, span := Start(tx.Statement.Context, "gorm "+operation,
+			semconv.DBSystemPostgreSQL,
+			attribute.String("db.operation.name", operation),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func (GormPlugin) after(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBQueryText(tx.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		RecordError(span, tx.Error)
+	}
+}
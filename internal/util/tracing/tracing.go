@@ -0,0 +1,125 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/EputraP/kfc_be"
+
+// otlpTracesPath is appended to the base endpoint, as for
+// OTEL_EXPORTER_OTLP_ENDPOINT in the OpenTelemetry specification.
+const otlpTracesPath = "/v1/traces"
+
+type Config struct {
+	Enabled     bool
+	ServiceName string
+	// OTLPEndpoint is the base URL of the collector's OTLP/HTTP receiver,
+	// e.g. http://localhost:4318. Spans are written to Stdout when it is
+	// empty.
+	OTLPEndpoint string
+	Stdout       io.Writer
+	// SampleRatio is the fraction of new traces recorded; traces started
+	// upstream follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+// Init installs the global tracer provider and the W3C trace context
+// propagator, and returns a function that flushes pending spans. Spans are
+// dropped when tracing is disabled.
+func Init(ctx context.Context, config Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if !config.Enabled {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	var exporter sdktrace.SpanExporter
+	var err error
+
+	if config.OTLPEndpoint != "" {
+		exporter, err = otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(strings.TrimRight(config.OTLPEndpoint, "/")+otlpTracesPath))
+	} else {
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(config.Stdout), stdouttrace.WithPrettyPrint())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(config.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServer starts the span of an incoming request as a child of the span
+// in ctx, usually extracted from the request headers.
+func StartServer(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// RecordError marks span as failed with err. It does nothing when err is nil.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// TraceID returns the id of the trace in ctx, or "" when there is none.
+func TraceID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasTraceID() {
+		return ""
+	}
+
+	return spanContext.TraceID().String()
+}
+
+// SpanID returns the id of the span in ctx, or "" when there is none.
+func SpanID(ctx context.Context) string {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.HasSpanID() {
+		return ""
+	}
+
+	return spanContext.SpanID().String()
+}
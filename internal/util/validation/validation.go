@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+	"github.com/gin-gonic/gin/binding"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	id_translations "github.com/go-playground/validator/v10/translations/id"
+)
+
+const (
+	TagUsername = "username"
+	TagPhone    = "phone"
+
+	// ruleType reports a JSON value of the wrong type.
+	ruleType = "type"
+	// messageDefault is the catalog message of rules without their own.
+	messageDefault = "default"
+)
+
+// patterns are the regular expressions of the custom rules. phone is E.164:
+// a plus sign, a country code that does not start with 0 and at most 15
+// digits in total.
+var patterns = map[string]*regexp.Regexp{
+	TagUsername: regexp.MustCompile(`^[A-Za-z0-9._-]+$`),
+	TagPhone:    regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`),
+}
+
+// FieldError describes one field that failed validation. Field is the JSON
+// path of the field, e.g. "phone_number".
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+
+	// source is the validator error Message is translated from, nil for
+	// JSON type errors.
+	source validator.FieldError
+}
+
+// FieldErrors are the details of REQUEST_VALIDATION_FAILED. They are
+// translated to the language of the request when the response is written.
+type FieldErrors []FieldError
+
+func (f FieldErrors) Translate(lang string) interface{} {
+	trans := i18n.Translator(lang)
+
+	translated := make(FieldErrors, len(f))
+	for i, fieldError := range f {
+		fieldError.Message = message(trans, fieldError)
+		translated[i] = fieldError
+	}
+
+	return translated
+}
+
+// Register makes gin's validator report JSON field names, adds the custom
+// rules and loads the validation messages of every supported language. It
+// must run after i18n.Init and before the first request is bound.
+func Register() error {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return errors.New("gin binding validator is not go-playground/validator")
+	}
+
+	validate.RegisterTagNameFunc(jsonFieldName)
+
+	for tag, pattern := range patterns {
+		if err := validate.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return pattern.MatchString(fl.Field().String())
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, lang := range i18n.Supported {
+		trans := i18n.Translator(lang)
+
+		var err error
+		switch lang {
+		case i18n.English:
+			err = en_translations.RegisterDefaultTranslations(validate, trans)
+		case i18n.Indonesian:
+			err = id_translations.RegisterDefaultTranslations(validate, trans)
+		}
+		if err != nil {
+			return fmt.Errorf("registering %s validation messages: %w", lang, err)
+		}
+
+		// The catalog adds the custom rules and may override the defaults
+		for key, text := range i18n.ValidationMessages(lang) {
+			if err := trans.Add(key, text, true); err != nil {
+				return fmt.Errorf("loading %s validation message %s: %w", lang, key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Error turns an error returned by ShouldBindJSON into the AppError to fail
+// the request with: REQUEST_VALIDATION_FAILED listing every failing field, or
+// REQUEST_INVALID_BODY when the body is not valid JSON.
+func Error(err error) error {
+	var validationErrors validator.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := make(FieldErrors, 0, len(validationErrors))
+		for _, fieldError := range validationErrors {
+			fieldErrors = append(fieldErrors, FieldError{
+				Field:  fieldPath(fieldError),
+				Rule:   fieldError.Tag(),
+				Param:  fieldError.Param(),
+				source: fieldError,
+			})
+		}
+
+		return errs.ValidationFailed.WithDetails(fieldErrors.Translate(i18n.Default()))
+	}
+
+	var typeError *json.UnmarshalTypeError
+	if errors.As(err, &typeError) && typeError.Field != "" {
+		fieldErrors := FieldErrors{{
+			Field: typeError.Field,
+			Rule:  ruleType,
+			Param: jsonType(typeError.Type),
+		}}
+
+		return errs.ValidationFailed.WithDetails(fieldErrors.Translate(i18n.Default()))
+	}
+
+	return errs.InvalidRequestBody.Wrap(err)
+}
+
+// Pattern returns the regular expression a custom rule matches values
+// against, for documenting it.
+func Pattern(tag string) (string, bool) {
+	pattern, ok := patterns[tag]
+	if !ok {
+		return "", false
+	}
+
+	return pattern.String(), true
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return field.Name
+	}
+
+	return name
+}
+
+// fieldPath drops the struct name the namespace starts with, so
+// "OTPVerifyBody.phone_number" becomes "phone_number".
+func fieldPath(fieldError validator.FieldError) string {
+	_, path, found := strings.Cut(fieldError.Namespace(), ".")
+	if !found {
+		return fieldError.Field()
+	}
+
+	return path
+}
+
+// message prefers the translation registered with the validator, then the
+// catalog message of the rule, then the catalog default.
+func message(trans ut.Translator, fieldError FieldError) string {
+	if fieldError.source != nil {
+		// Translate falls back to the Go error text without a translation
+		if message := fieldError.source.Translate(trans); message != fieldError.source.Error() {
+			return message
+		}
+	}
+
+	if message, err := trans.T(fieldError.Rule, fieldError.Field, fieldError.Param); err == nil {
+		return message
+	}
+	if message, err := trans.T(messageDefault, fieldError.Field, fieldError.Rule); err == nil {
+		return message
+	}
+
+	return fmt.Sprintf("%s failed the %s rule", fieldError.Field, fieldError.Rule)
+}
+
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return t.String()
+	}
+}
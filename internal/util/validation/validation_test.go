@@ -0,0 +1,137 @@
+package validation_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	errs "github.com/EputraP/kfc_be/internal/errors"
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type testAddress struct {
+	City string `json:"city" binding:"required"`
+}
+
+type testBody struct {
+	Username    string      `json:"username" binding:"required,username"`
+	PhoneNumber string      `json:"phone_number" binding:"omitempty,phone"`
+	Age         int         `json:"age" binding:"gte=17"`
+	Address     testAddress `json:"address"`
+}
+
+func TestMain(m *testing.M) {
+	if err := i18n.Init(i18n.Config{DefaultLanguage: i18n.English}); err != nil {
+		panic(err)
+	}
+	if err := validation.Register(); err != nil {
+		panic(err)
+	}
+
+	m.Run()
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    validation.FieldErrors
+		wantErr error
+	}{
+		{
+			name: "every failing field",
+			body: `{"username": "", "age": 12}`,
+			want: validation.FieldErrors{
+				{Field: "username", Rule: "required", Message: "username is a required field"},
+				{Field: "age", Rule: "gte", Param: "17", Message: "age must be 17 or greater"},
+				{Field: "address.city", Rule: "required", Message: "city is a required field"},
+			},
+			wantErr: errs.ValidationFailed,
+		},
+		{
+			name: "custom rules",
+			body: `{"username": "a b", "phone_number": "0812", "age": 20, "address": {"city": "Jakarta"}}`,
+			want: validation.FieldErrors{
+				{Field: "username", Rule: "username", Message: "username may only contain letters, digits, '.', '_' and '-'"},
+				{Field: "phone_number", Rule: "phone", Message: "phone_number must be a phone number in E.164 format, e.g. +628123456789"},
+			},
+			wantErr: errs.ValidationFailed,
+		},
+		{
+			name: "wrong json type",
+			body: `{"username": "alice", "age": "twenty"}`,
+			want: validation.FieldErrors{
+				{Field: "age", Rule: "type", Param: "number", Message: "age must be of type number"},
+			},
+			wantErr: errs.ValidationFailed,
+		},
+		{
+			name:    "malformed json",
+			body:    `{"username":`,
+			wantErr: errs.InvalidRequestBody,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body testBody
+			err := validation.Error(binding.JSON.BindBody([]byte(tt.body), &body))
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Error() = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				return
+			}
+
+			got := exported(errs.AsAppError(err).Details.(validation.FieldErrors))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("details = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldErrorsTranslate(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		lang string
+		want string
+	}{
+		{name: "rule in english", body: `{"username": "a b", "address": {"city": "Bogor"}}`, lang: i18n.English, want: "username may only contain letters, digits, '.', '_' and '-'"},
+		{name: "rule in indonesian", body: `{"username": "a b", "address": {"city": "Bogor"}}`, lang: i18n.Indonesian, want: "username hanya boleh berisi huruf, angka, '.', '_' dan '-'"},
+		{name: "built-in rule in indonesian", body: `{"address": {"city": "Bogor"}}`, lang: i18n.Indonesian, want: "username wajib diisi"},
+		{name: "type in indonesian", body: `{"age": "x"}`, lang: i18n.Indonesian, want: "age harus bertipe number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body testBody
+			err := validation.Error(binding.JSON.BindBody([]byte(tt.body), &body))
+
+			details, ok := errs.AsAppError(err).Details.(validation.FieldErrors)
+			if !ok || len(details) == 0 {
+				t.Fatalf("details = %#v, want validation.FieldErrors", errs.AsAppError(err).Details)
+			}
+
+			translated := details.Translate(tt.lang).(validation.FieldErrors)
+			if translated[0].Message != tt.want {
+				t.Errorf("Translate(%q)[0].Message = %q, want %q", tt.lang, translated[0].Message, tt.want)
+			}
+		})
+	}
+}
+
+// exported drops the unexported validator error of each field, which the
+// expected values cannot carry.
+func exported(fieldErrors validation.FieldErrors) validation.FieldErrors {
+	result := make(validation.FieldErrors, len(fieldErrors))
+	for i, f := range fieldErrors {
+		result[i] = validation.FieldError{Field: f.Field, Rule: f.Rule, Param: f.Param, Message: f.Message}
+	}
+
+	return result
+}
@@ -1,28 +1,74 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/EputraP/kfc_be/internal/config"
 	"github.com/EputraP/kfc_be/internal/constant"
+	errs "github.com/EputraP/kfc_be/internal/errors"
 	"github.com/EputraP/kfc_be/internal/handler"
 	"github.com/EputraP/kfc_be/internal/middleware"
+	"github.com/EputraP/kfc_be/internal/migrate"
 	"github.com/EputraP/kfc_be/internal/repository"
 	"github.com/EputraP/kfc_be/internal/routes"
+	"github.com/EputraP/kfc_be/internal/server"
 	"github.com/EputraP/kfc_be/internal/service"
 	dbstore "github.com/EputraP/kfc_be/internal/store"
+	"github.com/EputraP/kfc_be/internal/util/apiversion"
+	"github.com/EputraP/kfc_be/internal/util/cookie"
+	"github.com/EputraP/kfc_be/internal/util/directory"
 	"github.com/EputraP/kfc_be/internal/util/hasher"
+	"github.com/EputraP/kfc_be/internal/util/health"
+	"github.com/EputraP/kfc_be/internal/util/i18n"
+	"github.com/EputraP/kfc_be/internal/util/identityprovider"
 	"github.com/EputraP/kfc_be/internal/util/logger"
+	"github.com/EputraP/kfc_be/internal/util/mailer"
+	"github.com/EputraP/kfc_be/internal/util/metrics"
+	"github.com/EputraP/kfc_be/internal/util/response"
+	"github.com/EputraP/kfc_be/internal/util/sms"
 	"github.com/EputraP/kfc_be/internal/util/tokenprovider"
+	"github.com/EputraP/kfc_be/internal/util/tracing"
+	"github.com/EputraP/kfc_be/internal/util/validation"
+	"github.com/EputraP/kfc_be/migration"
 	"github.com/gin-gonic/gin"
-	"github.com/lpernett/godotenv"
+	"github.com/go-webauthn/webauthn/webauthn"
 )
 
 func main() {
 
-	if err := logger.Init("app.log"); err != nil {
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Error("main", "Error loading configuration", map[string]string{
+			"error": err.Error(),
+		})
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if err := logger.Init(logger.Config{
+		Level:   cfg.Log.Level,
+		Format:  cfg.Log.Format,
+		Outputs: cfg.Log.Outputs,
+		File: logger.FileConfig{
+			Path:       cfg.Log.File,
+			MaxSizeMB:  cfg.Log.MaxSizeMB,
+			MaxBackups: cfg.Log.MaxBackups,
+			MaxAgeDays: int(cfg.Log.MaxAge / (24 * time.Hour)),
+			Compress:   cfg.Log.Compress,
+		},
+		Redaction: logger.RedactionConfig{
+			Keys:    cfg.Log.RedactKeys,
+			HashPII: cfg.Log.HashPII,
+			HashKey: cfg.Log.PIIHashKey,
+		},
+	}); err != nil {
 		logger.Error("main", "Failed to initialize logger:", map[string]string{
 			"error": err.Error(),
 		})
@@ -31,83 +77,479 @@ func main() {
 
 	logger.Info("main", "Starting application...", nil)
 
-	err := godotenv.Load()
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(cfg, os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "openapi" {
+		os.Exit(runOpenAPI(cfg, os.Args[2:]))
+	}
+
+	if err := cfg.Validate(); err != nil {
+		logger.Error("main", "Invalid configuration", map[string]string{
+			"error": err.Error(),
+		})
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	if cfg.IsProduction() {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:      cfg.Tracing.Enabled,
+		ServiceName:  cfg.Tracing.ServiceName,
+		OTLPEndpoint: cfg.Tracing.OTLPEndpoint,
+		Stdout:       os.Stdout,
+		SampleRatio:  cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		logger.Error("main", "Error loading .env file", map[string]string{
+		logger.Error("main", "Error initializing tracing", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	logger.Info("main", "Initializing db connection...", nil)
+	if err := dbstore.Init(cfg.Database); err != nil {
+		logger.Error("main", "Error connecting to database", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	if err := dbstore.RegisterMetrics(); err != nil {
+		logger.Error("main", "Error registering database metrics", map[string]string{
 			"error": err.Error(),
 		})
 		return
 	}
 
-	handlers, middlewares := prepare()
+	if cfg.AutoMigrate {
+		logger.Info("main", "Applying database migrations...", nil)
+		if err := autoMigrate(); err != nil {
+			logger.Error("main", "Failed to apply migrations", map[string]string{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
 
-	srv := gin.Default()
+	if err := i18n.Init(i18n.Config{DefaultLanguage: cfg.I18n.DefaultLanguage}); err != nil {
+		logger.Error("main", "Failed to load message catalogs", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
 
-	srv.Use(middleware.CORS())
+	if err := validation.Register(); err != nil {
+		logger.Error("main", "Failed to register validators", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	readiness := &server.Readiness{}
 
-	routes.Build(srv, handlers, middlewares)
+	handlers, middlewares, err := prepare(cfg, readiness)
+	if err != nil {
+		logger.Error("main", "Error initializing application", map[string]string{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	// Access logs are written by middleware.RequestContext
+	srv := gin.New()
+	srv.Use(middleware.Tracing())
+	srv.Use(middleware.RequestContext())
+	srv.Use(middleware.Language())
+	srv.Use(middleware.Metrics())
+	srv.Use(middleware.CORS())
+	srv.Use(middleware.ErrorHandler(middleware.ErrorHandlerConfig{
+		Problem: cfg.Server.ErrorFormat == config.ErrorFormatProblem,
+	}))
+	srv.Use(middleware.Recovery())
+	srv.NoRoute(func(c *gin.Context) {
+		response.Fail(c, errs.RouteNotFound)
+	})
 
-	port := os.Getenv("PORT")
+	versions := apiVersions(cfg.API)
+	routes.Build(srv, handlers, middlewares, versions)
 
-	if port == "" {
-		port = "8080"
+	httpServer := server.New(server.ServerConfig{
+		Addr:            fmt.Sprintf(":%s", cfg.Server.Port),
+		Handler:         srv,
+		ReadTimeout:     cfg.Server.ReadTimeout,
+		WriteTimeout:    cfg.Server.WriteTimeout,
+		IdleTimeout:     cfg.Server.IdleTimeout,
+		DrainDelay:      cfg.Server.DrainDelay,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		Readiness:       readiness,
+	})
+	httpServer.Go("metrics", func(ctx context.Context) {
+		serveMetrics(ctx, fmt.Sprintf(":%s", cfg.Server.MetricsPort))
+	})
+	if cfg.Log.RotateInterval > 0 {
+		httpServer.Go("log rotation", func(ctx context.Context) {
+			rotateLogs(ctx, cfg.Log.RotateInterval)
+		})
 	}
+	httpServer.OnShutdown("tracing", shutdownTracing)
+	httpServer.OnShutdown("database", func(ctx context.Context) error {
+		return dbstore.Close()
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		// A second signal kills the process instead of waiting for the drain
+		<-ctx.Done()
+		stop()
+	}()
 
 	logger.Info("main",
 		"Server is starting...", map[string]string{
-			"port": port,
+			"port": cfg.Server.Port,
 		})
 
-	if err := srv.Run(fmt.Sprintf(":%s", port)); err != nil {
-		logger.Error("main", "Error running gin server:", map[string]string{
+	if err := httpServer.Run(ctx); err != nil {
+		logger.Error("main", "Error running server:", map[string]string{
 			"error": err.Error(),
 		})
 	}
 
 }
 
-func prepare() (handlers *routes.Handlers, middlewares *routes.Middlewares) {
+// serveMetrics serves /metrics on addr until ctx is cancelled. Workers stop
+// after the API drained, so the last scrape still sees the final requests.
+func serveMetrics(ctx context.Context, addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	metricsServer := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		metricsServer.Close()
+	}()
+
+	logger.Info("main", "Metrics server is starting...", map[string]string{
+		"addr": addr,
+	})
+
+	if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("main", "Error running metrics server", map[string]string{
+			"error": err.Error(),
+		})
+	}
+}
+
+// rotateLogs starts a new log file every interval until ctx is cancelled.
+func rotateLogs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := logger.Rotate(); err != nil {
+				logger.Error("main", "Error rotating log file", map[string]string{
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+}
+
+func prepare(cfg *config.Config, readiness *server.Readiness) (handlers *routes.Handlers, middlewares *routes.Middlewares, err error) {
 	logger.Info("main", "Initializing JWT...", nil)
 
 	hasher := hasher.NewBcrypt(10)
-	appName := os.Getenv(constant.EnvKeyAppName)
-	jwtSecret := os.Getenv(constant.EnvKeyJWTSecret)
-	refreshTokenDurationStr := os.Getenv(constant.EnvKeyRefreshTokenDuration)
 
-	accessTokenDurationStr := os.Getenv(constant.EnvKeyAccessTokenDuration)
+	accessTokenDuration := minutes(cfg.JWT.AccessTokenDuration)
+	refreshTokenDuration := minutes(cfg.JWT.RefreshTokenDuration)
 
-	refreshTokenDuration, err := strconv.Atoi(refreshTokenDurationStr)
-	if err != nil {
-		log.Fatalln("error creating handlers and middleware", err)
+	jwtProvider := tokenprovider.GetProvider(cfg.JWT)
+
+	cookieConfig := cookie.NewConfig(
+		cfg.Cookie.Enabled,
+		cfg.Cookie.Domain,
+		cfg.Cookie.Path,
+		cfg.Cookie.SameSite,
+		cfg.Cookie.Secure,
+		accessTokenDuration,
+		refreshTokenDuration,
+	)
+
+	magicLinkDuration := minutes(cfg.MagicLink.Duration)
+
+	var mail mailer.Mailer
+	switch cfg.Mailer.Driver {
+	case "smtp":
+		mail = mailer.NewSMTP(
+			cfg.Mailer.SMTPHost,
+			cfg.Mailer.SMTPPort,
+			cfg.Mailer.SMTPUsername,
+			cfg.Mailer.SMTPPassword,
+			cfg.Mailer.SMTPFrom,
+		)
+	default:
+		mail = mailer.NewLog(cfg.Log.DeliveryContent)
 	}
 
-	accessTokenDuration, err := strconv.Atoi(accessTokenDurationStr)
-	if err != nil {
-		log.Fatalln("error creating handlers and middlewares", err)
+	var smsSender sms.SMSSender
+	switch cfg.SMS.Driver {
+	case "http":
+		smsSender = sms.NewHTTP(cfg.SMS.GatewayURL, cfg.SMS.GatewayAPIKey)
+	default:
+		smsSender = sms.NewLog(cfg.Log.DeliveryContent)
 	}
 
-	jwtProvider := tokenprovider.NewJWT(appName, jwtSecret, refreshTokenDuration, accessTokenDuration)
+	webAuthnChallengeDuration := minutes(cfg.WebAuthn.ChallengeDuration)
 
-	middlewares = &routes.Middlewares{
-		Auth: middleware.CreateAuth(jwtProvider),
+	var webAuthn *webauthn.WebAuthn
+	if cfg.WebAuthn.Enabled() {
+		webAuthn, err = webauthn.New(&webauthn.Config{
+			RPID:          cfg.WebAuthn.RPID,
+			RPDisplayName: cfg.WebAuthn.RPDisplayName,
+			RPOrigins:     cfg.WebAuthn.RPOrigins,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating webauthn relying party: %w", err)
+		}
+	}
+
+	oauthStateDuration := minutes(cfg.OAuth.StateDuration)
+
+	var identityProviders []identityprovider.Provider
+	for _, provider := range cfg.OAuth.Providers {
+		identityProviders = append(identityProviders, identityprovider.NewOIDC(identityprovider.Config{
+			Name:         provider.Name,
+			IssuerURL:    provider.IssuerURL,
+			ClientID:     provider.ClientID,
+			ClientSecret: provider.ClientSecret,
+			RedirectURL:  provider.RedirectURL,
+			Scopes:       provider.Scopes,
+		}))
 	}
 
-	logger.Info("main", "Initializing db connection...", nil)
 	db := dbstore.Get()
 
 	logger.Info("main", "Initializing repositories...", nil)
+	txManager := repository.NewTxManager(db)
 	authRepo := repository.NewAuthRepository(db)
+	magicLinkRepo := repository.NewMagicLinkRepository(db)
+	otpRepo := repository.NewOTPRepository(db)
+	webAuthnRepo := repository.NewWebAuthnRepository(db)
+	oauthRepo := repository.NewOAuthRepository(db)
+	roleRepo := repository.NewRoleRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	organizationRepo := repository.NewOrganizationRepository(db)
+	membershipRepo := repository.NewMembershipRepository(db)
 
 	logger.Info("main", "Initializing services...", nil)
-	authService := service.NewAuthService(service.AuthServiceConfig{AuthRepo: authRepo, Hasher: hasher, JwtProvider: jwtProvider})
+	authenticator := newAuthenticator(cfg.LDAP, txManager, authRepo, oauthRepo, roleRepo, hasher)
+	authService := service.NewAuthService(service.AuthServiceConfig{TxManager: txManager, AuthRepo: authRepo, Hasher: hasher, JwtProvider: jwtProvider, OrganizationRepo: organizationRepo, Authenticator: authenticator})
+	magicLinkService := service.NewMagicLinkService(service.MagicLinkServiceConfig{
+		TxManager:        txManager,
+		AuthRepo:         authRepo,
+		MagicLinkRepo:    magicLinkRepo,
+		JwtProvider:      jwtProvider,
+		OrganizationRepo: organizationRepo,
+		Mailer:           mail,
+		Secret:           cfg.JWT.Secret,
+		LinkURL:          cfg.MagicLink.URL,
+		LinkDuration:     magicLinkDuration,
+	})
+	otpService := service.NewOTPService(service.OTPServiceConfig{
+		TxManager:        txManager,
+		AuthRepo:         authRepo,
+		OTPRepo:          otpRepo,
+		Hasher:           hasher,
+		JwtProvider:      jwtProvider,
+		OrganizationRepo: organizationRepo,
+		SMSSender:        smsSender,
+		Secret:           cfg.JWT.Secret,
+		Duration:         minutes(cfg.OTP.Duration),
+		MaxAttempts:      cfg.OTP.MaxAttempts,
+		RequestLimit:     cfg.OTP.RequestLimit,
+		RequestWindow:    cfg.OTP.RequestWindow,
+	})
+	webAuthnService := service.NewWebAuthnService(service.WebAuthnServiceConfig{
+		AuthRepo:          authRepo,
+		WebAuthnRepo:      webAuthnRepo,
+		JwtProvider:       jwtProvider,
+		OrganizationRepo:  organizationRepo,
+		WebAuthn:          webAuthn,
+		ChallengeDuration: webAuthnChallengeDuration,
+	})
+	oauthService := service.NewOAuthService(service.OAuthServiceConfig{
+		TxManager:        txManager,
+		AuthRepo:         authRepo,
+		OAuthRepo:        oauthRepo,
+		Hasher:           hasher,
+		JwtProvider:      jwtProvider,
+		OrganizationRepo: organizationRepo,
+		Providers:        identityprovider.NewRegistry(identityProviders...),
+		Secret:           cfg.JWT.Secret,
+		StateDuration:    oauthStateDuration,
+	})
+	roleService := service.NewRoleService(service.RoleServiceConfig{RoleRepo: roleRepo})
+	impersonationService := service.NewImpersonationService(service.ImpersonationServiceConfig{
+		AuthRepo:    authRepo,
+		RoleService: roleService,
+		JwtProvider: jwtProvider,
+		Duration:    minutes(cfg.Impersonation.TokenDuration),
+	})
+	organizationService := service.NewOrganizationService(service.OrganizationServiceConfig{
+		TxManager:          txManager,
+		AuthRepo:           authRepo,
+		OrganizationRepo:   organizationRepo,
+		MembershipRepo:     membershipRepo,
+		JwtProvider:        jwtProvider,
+		Mailer:             mail,
+		Secret:             cfg.JWT.Secret,
+		InvitationURL:      cfg.Organization.InvitationURL,
+		InvitationDuration: int(cfg.Organization.InvitationDuration / time.Hour),
+	})
+	sessionService := service.NewSessionService(service.SessionServiceConfig{SessionRepo: sessionRepo})
+	scimService := service.NewScimService(service.ScimServiceConfig{
+		TxManager:   txManager,
+		AuthRepo:    authRepo,
+		UserRepo:    userRepo,
+		RoleRepo:    roleRepo,
+		SessionRepo: sessionRepo,
+		Hasher:      hasher,
+	})
+
+	migrator, err := migrate.New(db, migration.FS())
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading migrations: %w", err)
+	}
+
+	healthChecks := map[string]health.Checker{
+		"postgres": health.CheckFunc(dbstore.Ping),
+		"migrations": health.CheckFunc(func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if pending > 0 {
+				return fmt.Errorf("%d pending migration(s)", pending)
+			}
+			return nil
+		}),
+	}
+	if checker, ok := mail.(health.Checker); ok {
+		healthChecks["mailer"] = checker
+	}
+
+	healthService := service.NewHealthService(service.HealthServiceConfig{Readiness: readiness, Checks: healthChecks})
+
+	logger.Info("main", "Initializing middlewares...", nil)
+	middlewares = &routes.Middlewares{
+		Auth:         middleware.CreateAuth(jwtProvider, middleware.AuthConfig{Cookie: cookieConfig, TokenCookie: constant.CookieAccessToken, Sessions: sessionService}),
+		RefreshAuth:  middleware.CreateAuth(jwtProvider, middleware.AuthConfig{Cookie: cookieConfig, TokenCookie: constant.CookieRefreshToken, Sessions: sessionService}),
+		Scim:         middleware.CreateSCIMAuth(cfg.SCIM.BearerToken),
+		Admin:        middleware.CreateRequireRole(roleService, constant.RoleAdmin),
+		Organization: middleware.CreateRequireOrganization(organizationService),
+	}
 
 	logger.Info("main", "Initializing handlers...", nil)
-	authHandler := handler.NewAuthHandler(handler.AuthHandlerConfig{AuthService: authService, TokenProvider: jwtProvider})
+	authHandler := handler.NewAuthHandler(handler.AuthHandlerConfig{AuthService: authService, TokenProvider: jwtProvider, Cookie: cookieConfig})
+
+	magicLinkHandler := handler.NewMagicLinkHandler(handler.MagicLinkHandlerConfig{MagicLinkService: magicLinkService, Cookie: cookieConfig, LinkDuration: magicLinkDuration})
+	otpHandler := handler.NewOTPHandler(handler.OTPHandlerConfig{OTPService: otpService, Cookie: cookieConfig})
+	webAuthnHandler := handler.NewWebAuthnHandler(handler.WebAuthnHandlerConfig{WebAuthnService: webAuthnService, Cookie: cookieConfig, ChallengeDuration: webAuthnChallengeDuration})
+	oauthHandler := handler.NewOAuthHandler(handler.OAuthHandlerConfig{OAuthService: oauthService, Cookie: cookieConfig, StateDuration: oauthStateDuration})
+	scimHandler := handler.NewScimHandler(handler.ScimHandlerConfig{ScimService: scimService})
+	adminHandler := handler.NewAdminHandler(handler.AdminHandlerConfig{ImpersonationService: impersonationService})
+	healthHandler := handler.NewHealthHandler(handler.HealthHandlerConfig{HealthService: healthService})
+	organizationHandler := handler.NewOrganizationHandler(handler.OrganizationHandlerConfig{OrganizationService: organizationService, Cookie: cookieConfig})
 
 	handlers = &routes.Handlers{
-		Auth: authHandler,
+		Auth:         authHandler,
+		MagicLink:    magicLinkHandler,
+		OTP:          otpHandler,
+		WebAuthn:     webAuthnHandler,
+		OAuth:        oauthHandler,
+		Scim:         scimHandler,
+		Admin:        adminHandler,
+		Organization: organizationHandler,
+		Health:       healthHandler,
 	}
 
 	logger.Info("main", "Application initialized successfully.", nil)
 	return
 }
+
+func newAuthenticator(config config.LDAP, txManager repository.TxManager, authRepo repository.AuthRepository, oauthRepo repository.OAuthRepository, roleRepo repository.RoleRepository, hasher hasher.Hasher) service.Authenticator {
+	databaseAuthenticator := service.NewDatabaseAuthenticator(authRepo, hasher)
+
+	if config.URL == "" {
+		return databaseAuthenticator
+	}
+
+	ldapAuthenticator := service.NewLDAPAuthenticator(service.LDAPAuthenticatorConfig{
+		TxManager: txManager,
+		AuthRepo:  authRepo,
+		OAuthRepo: oauthRepo,
+		RoleRepo:  roleRepo,
+		Hasher:    hasher,
+		Directory: directory.NewLDAP(directory.LDAPConfig{
+			URL:            config.URL,
+			StartTLS:       config.StartTLS,
+			BindDN:         config.BindDN,
+			BindPassword:   config.BindPassword,
+			BaseDN:         config.BaseDN,
+			UserFilter:     config.UserFilter,
+			EmailAttribute: config.EmailAttribute,
+			GroupAttribute: config.GroupAttribute,
+		}),
+		GroupRoles: config.GroupRoles,
+	})
+
+	domainAuthenticators := map[string]service.Authenticator{}
+	for _, domain := range config.Domains {
+		domainAuthenticators[domain] = ldapAuthenticator
+	}
+
+	defaultAuthenticator := databaseAuthenticator
+	if config.DefaultAuthenticator == "ldap" {
+		defaultAuthenticator = ldapAuthenticator
+	}
+
+	return service.NewAuthenticatorSelector(defaultAuthenticator, domainAuthenticators)
+}
+
+// apiVersions returns the versions the API is served in: v1 and, while
+// clients migrate, its deprecated unversioned aliases.
+func apiVersions(config config.API) []apiversion.Version {
+	versions := []apiversion.Version{{Name: apiversion.V1}}
+
+	if config.ServeUnversioned {
+		versions = append(versions, apiversion.Version{
+			Name:        apiversion.Unversioned,
+			Deprecation: config.UnversionedDeprecationDate(),
+			Sunset:      config.UnversionedSunsetDate(),
+			Successor:   apiversion.V1,
+		})
+	}
+
+	return versions
+}
+
+// minutes converts a configured lifetime to the whole minutes services take.
+func minutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
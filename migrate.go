@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/EputraP/kfc_be/internal/config"
+	"github.com/EputraP/kfc_be/internal/migrate"
+	dbstore "github.com/EputraP/kfc_be/internal/store"
+	"github.com/EputraP/kfc_be/migration"
+)
+
+const migrateUsage = `usage: kfc_be migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [n]       revert the last n migrations (default 1)
+  status         list migrations and when they were applied
+  create <name>  create an empty up/down migration pair
+`
+
+// runMigrate implements the "migrate" subcommand and returns the process
+// exit code.
+func runMigrate(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	dir := flags.String("dir", migration.Dir, "directory new migrations are created in")
+	flags.Usage = func() { fmt.Fprint(os.Stderr, migrateUsage) }
+
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	command := flags.Arg(0)
+
+	if command == "create" {
+		if flags.NArg() < 2 {
+			flags.Usage()
+			return 2
+		}
+
+		upPath, downPath, err := migrate.Create(*dir, flags.Arg(1))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		fmt.Printf("created %s\ncreated %s\n", upPath, downPath)
+		return 0
+	}
+
+	if err := cfg.Database.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := dbstore.Init(cfg.Database); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	migrator, err := migrate.New(dbstore.Get(), migration.FS())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	ctx := context.Background()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+	case "down":
+		steps := 1
+		if flags.NArg() > 1 {
+			steps, err = strconv.Atoi(flags.Arg(1))
+			if err != nil || steps < 1 {
+				flags.Usage()
+				return 2
+			}
+		}
+
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Printf("reverted %d migration(s)\n", reverted)
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d  %-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+	default:
+		flags.Usage()
+		return 2
+	}
+
+	return 0
+}
+
+// autoMigrate applies pending migrations before the server starts.
+func autoMigrate() error {
+	migrator, err := migrate.New(dbstore.Get(), migration.FS())
+	if err != nil {
+		return err
+	}
+
+	_, err = migrator.Up(context.Background())
+	return err
+}
@@ -0,0 +1,21 @@
+// Package migration embeds the versioned SQL migrations. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql.
+package migration
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed sql/*.sql
+var embedded embed.FS
+
+// Dir is the directory, relative to the repository root, new migrations are
+// created in.
+const Dir = "migration/sql"
+
+// FS returns the embedded migration files.
+func FS() fs.FS {
+	files, _ := fs.Sub(embedded, "sql")
+	return files
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_details;
+DROP TABLE IF EXISTS users;
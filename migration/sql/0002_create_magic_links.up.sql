@@ -0,0 +1,13 @@
+CREATE TABLE magic_links (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	user_id uuid NOT NULL,
+	token_hash varchar NOT NULL,
+	nonce_hash varchar NOT NULL,
+	expires_at timestamptz NOT NULL,
+	used_at timestamptz NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT magic_links_pkey PRIMARY KEY (id),
+	CONSTRAINT magic_links_token_hash_key UNIQUE (token_hash)
+);
+
+ALTER TABLE ONLY magic_links ADD CONSTRAINT fk_magic_links_user FOREIGN KEY (user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1,12 @@
+CREATE TABLE otp_codes (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	phone_number varchar NOT NULL,
+	code_hash varchar NOT NULL,
+	attempts int NOT NULL DEFAULT 0,
+	expires_at timestamptz NOT NULL,
+	consumed_at timestamptz NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT otp_codes_pkey PRIMARY KEY (id)
+);
+
+CREATE INDEX otp_codes_phone_number_idx ON otp_codes (phone_number, created_at DESC);
@@ -0,0 +1,33 @@
+CREATE TABLE webauthn_credentials (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	user_id uuid NOT NULL,
+	credential_id bytea NOT NULL,
+	public_key bytea NOT NULL,
+	attestation_type varchar NULL,
+	aaguid bytea NULL,
+	sign_count bigint NOT NULL DEFAULT 0,
+	transports varchar NULL,
+	attachment varchar NULL,
+	user_present boolean NOT NULL DEFAULT false,
+	user_verified boolean NOT NULL DEFAULT false,
+	backup_eligible boolean NOT NULL DEFAULT false,
+	backup_state boolean NOT NULL DEFAULT false,
+	clone_warning boolean NOT NULL DEFAULT false,
+	last_used_at timestamptz NULL,
+	created_at timestamptz NULL,
+	updated_at timestamptz NULL,
+	CONSTRAINT webauthn_credentials_pkey PRIMARY KEY (id),
+	CONSTRAINT webauthn_credentials_credential_id_key UNIQUE (credential_id)
+);
+
+ALTER TABLE ONLY webauthn_credentials ADD CONSTRAINT fk_webauthn_credentials_user FOREIGN KEY (user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE webauthn_challenges (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	user_id uuid NULL,
+	ceremony varchar NOT NULL,
+	session_data jsonb NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT webauthn_challenges_pkey PRIMARY KEY (id)
+);
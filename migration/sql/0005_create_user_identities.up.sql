@@ -0,0 +1,25 @@
+CREATE TABLE user_identities (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	user_id uuid NOT NULL,
+	provider varchar NOT NULL,
+	subject varchar NOT NULL,
+	email varchar NULL,
+	created_at timestamptz NULL,
+	updated_at timestamptz NULL,
+	CONSTRAINT user_identities_pkey PRIMARY KEY (id),
+	CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject)
+);
+
+ALTER TABLE ONLY user_identities ADD CONSTRAINT fk_user_identities_user FOREIGN KEY (user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE oauth_states (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	provider varchar NOT NULL,
+	state_hash varchar NOT NULL,
+	nonce varchar NOT NULL,
+	code_verifier varchar NOT NULL,
+	expires_at timestamptz NOT NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT oauth_states_pkey PRIMARY KEY (id),
+	CONSTRAINT oauth_states_state_hash_key UNIQUE (state_hash)
+);
@@ -0,0 +1,11 @@
+CREATE TABLE user_roles (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	user_id uuid NOT NULL,
+	"role" varchar NOT NULL,
+	"source" varchar NOT NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT user_roles_pkey PRIMARY KEY (id),
+	CONSTRAINT user_roles_user_role_source_key UNIQUE (user_id, "role", "source")
+);
+
+ALTER TABLE ONLY user_roles ADD CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE;
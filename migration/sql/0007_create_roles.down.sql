@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS roles;
+
+ALTER TABLE users DROP COLUMN IF EXISTS sessions_revoked_at;
+ALTER TABLE users DROP COLUMN IF EXISTS external_id;
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN external_id varchar NULL;
+ALTER TABLE users ADD COLUMN sessions_revoked_at timestamptz NULL;
+
+CREATE TABLE roles (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	"name" varchar NOT NULL,
+	created_at timestamptz NULL,
+	updated_at timestamptz NULL,
+	CONSTRAINT roles_pkey PRIMARY KEY (id),
+	CONSTRAINT roles_name_key UNIQUE ("name")
+);
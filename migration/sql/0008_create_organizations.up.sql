@@ -0,0 +1,41 @@
+CREATE TABLE organizations (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	"name" varchar NOT NULL,
+	created_by uuid NOT NULL,
+	created_at timestamptz NULL,
+	updated_at timestamptz NULL,
+	deleted_at timestamptz NULL,
+	CONSTRAINT organizations_pkey PRIMARY KEY (id)
+);
+
+ALTER TABLE ONLY organizations ADD CONSTRAINT fk_organizations_created_by FOREIGN KEY (created_by) REFERENCES users(id) ON UPDATE CASCADE;
+
+CREATE TABLE memberships (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	organization_id uuid NOT NULL,
+	user_id uuid NOT NULL,
+	"role" varchar NOT NULL,
+	created_at timestamptz NULL,
+	updated_at timestamptz NULL,
+	CONSTRAINT memberships_pkey PRIMARY KEY (id),
+	CONSTRAINT memberships_organization_user_key UNIQUE (organization_id, user_id)
+);
+
+ALTER TABLE ONLY memberships ADD CONSTRAINT fk_memberships_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON UPDATE CASCADE ON DELETE CASCADE;
+ALTER TABLE ONLY memberships ADD CONSTRAINT fk_memberships_user FOREIGN KEY (user_id) REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE;
+
+CREATE TABLE organization_invitations (
+	id uuid DEFAULT public.uuid_generate_v4(),
+	organization_id uuid NOT NULL,
+	email varchar NOT NULL,
+	"role" varchar NOT NULL,
+	token_hash varchar NOT NULL,
+	invited_by uuid NOT NULL,
+	expires_at timestamptz NOT NULL,
+	accepted_at timestamptz NULL,
+	created_at timestamptz NULL,
+	CONSTRAINT organization_invitations_pkey PRIMARY KEY (id),
+	CONSTRAINT organization_invitations_token_hash_key UNIQUE (token_hash)
+);
+
+ALTER TABLE ONLY organization_invitations ADD CONSTRAINT fk_organization_invitations_organization FOREIGN KEY (organization_id) REFERENCES organizations(id) ON UPDATE CASCADE ON DELETE CASCADE;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_details_phone_number_key;
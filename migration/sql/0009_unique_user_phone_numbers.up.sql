@@ -0,0 +1,2 @@
+-- A phone number logs in to a single account, also once it is deactivated
+CREATE UNIQUE INDEX user_details_phone_number_key ON user_details (phone_number) WHERE phone_number <> '' AND deleted_at IS NULL;
@@ -0,0 +1,2 @@
+-- Usernames stay taken after deactivation, so the index covers every row
+CREATE UNIQUE INDEX users_username_key ON users (username);
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/EputraP/kfc_be/internal/config"
+	"github.com/EputraP/kfc_be/internal/routes"
+	"github.com/EputraP/kfc_be/internal/util/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+const openAPIUsage = `usage: kfc_be openapi [file]
+
+Checks that the OpenAPI document matches the registered routes and writes it
+to file, or to stdout. It needs no database, so CI can run it to catch routes
+added without documentation.
+`
+
+// runOpenAPI implements the "openapi" subcommand and returns the process
+// exit code.
+func runOpenAPI(cfg *config.Config, args []string) int {
+	if len(args) > 1 {
+		fmt.Fprint(os.Stderr, openAPIUsage)
+		return 2
+	}
+
+	// The handlers are never called, only the routes are needed
+	gin.SetMode(gin.ReleaseMode)
+	srv := gin.New()
+	versions := apiVersions(cfg.API)
+	routes.Build(srv, &routes.Handlers{}, &routes.Middlewares{}, versions)
+
+	spec := routes.Spec(versions)
+	if err := openapi.Check(spec, srv.Routes()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	body, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	body = append(body, '\n')
+
+	if len(args) == 0 {
+		_, _ = os.Stdout.Write(body)
+		return 0
+	}
+
+	if err := os.WriteFile(args[0], body, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	return 0
+}